@@ -0,0 +1,348 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"kit.workmate/live-agent/internal/config"
+	"kit.workmate/live-agent/internal/health"
+	"kit.workmate/live-agent/internal/system/display"
+	"kit.workmate/live-agent/internal/system/usb"
+)
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), `Usage: workmate-live-agent [-config file] [command]
+
+Without a command the agent serves its API.
+
+Commands:
+  doctor                  check the machine and suggest fixes
+  status [--json]         collect the status once and print it
+  config validate         check the config file
+  config print-defaults   print the default config as YAML
+
+Flags:
+`)
+	flag.PrintDefaults()
+}
+
+// runCommand runs a one-shot subcommand and returns the exit code.
+func runCommand(configPath string, args []string) int {
+	// Config and probe logging would mix with the output
+	log.SetOutput(io.Discard)
+
+	switch args[0] {
+	case "doctor":
+		return doctorCommand(configPath, args[1:])
+	case "status":
+		return statusCommand(configPath, args[1:])
+	case "config":
+		if len(args) > 1 {
+			switch args[1] {
+			case "validate":
+				return validateCommand(configPath, args[2:])
+			case "print-defaults":
+				return printDefaultsCommand()
+			}
+		}
+	case "help":
+		usage()
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", strings.Join(args, " "))
+	usage()
+	return 2
+}
+
+// commandFlags lets -config also follow the command.
+func commandFlags(name, configPath string) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	path := fs.String("config", configPath, "path to config file")
+	return fs, path
+}
+
+func statusCommand(configPath string, args []string) int {
+	fs, path := commandFlags("status", configPath)
+	asJSON := fs.Bool("json", false, "print status and capabilities as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load(*path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	snapshot, err := collectOnce(cfg.Health)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(snapshot)
+	} else {
+		printStatus(os.Stdout, snapshot)
+	}
+
+	// Failed probes are in the output; still tell scripts about them
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// collectOnce runs the probes like one poll of the agent would.
+func collectOnce(cfg config.HealthConfig) (health.Snapshot, error) {
+	collector := health.NewCollector(cfg)
+
+	// CPU and network rates are measured against a previous sample
+	if cfg.Checks.Load {
+		_, _ = collector.Collect()
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	status, err := collector.Collect()
+	return health.Snapshot{
+		Status:       status,
+		Capabilities: health.CollectCapabilities(status, cfg.CapabilityRules()),
+	}, err
+}
+
+func printStatus(out io.Writer, snapshot health.Snapshot) {
+	s := snapshot.Status
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	display := "yes"
+	if s.Headless {
+		display = "no (headless)"
+	} else if s.Display.Available {
+		display = describeSessions(s.Display)
+	}
+	fmt.Fprintf(w, "Host\t%s\n", s.Hostname)
+	fmt.Fprintf(w, "Display\t%s\n", display)
+	for _, m := range s.Display.Monitors {
+		fmt.Fprintf(w, "Monitor\t%s\n", describeMonitor(m))
+	}
+	switch {
+	case len(s.Video.Details) > 0:
+		for _, dev := range s.Video.Details {
+			if dev.Identity.ID != "" {
+				fmt.Fprintf(w, "Video\t%s (%s)\n", dev.Path, dev.Identity.ID)
+			} else {
+				fmt.Fprintf(w, "Video\t%s\n", dev.Path)
+			}
+		}
+	case s.Video.DeviceCount > 0:
+		fmt.Fprintf(w, "Video\t%s\n", strings.Join(s.Video.Devices, ", "))
+	default:
+		fmt.Fprintf(w, "Video\tnone\n")
+	}
+
+	audio := s.Audio.Backend
+	if s.Audio.Ready {
+		audio += fmt.Sprintf(", ready (%d sources, %d sinks)", len(s.Audio.Sources), len(s.Audio.Sinks))
+	} else {
+		audio += ", not ready"
+	}
+	fmt.Fprintf(w, "Audio\t%s\n", audio)
+
+	obsState := "not running"
+	if s.OBS.Running && s.OBS.Process != nil {
+		obsState = fmt.Sprintf("running (pid %d, %s)", s.OBS.Process.PID, s.OBS.Process.InstallType)
+	} else if s.OBS.Running {
+		obsState = "running"
+	}
+	fmt.Fprintf(w, "OBS\t%s\n", obsState)
+
+	for _, dev := range s.ExpectedDevices {
+		state := "missing"
+		if dev.Present {
+			state = "present (" + strings.Join(dev.Paths, ", ") + ")"
+		}
+		fmt.Fprintf(w, "Expected\t%s %s: %s\n", dev.Kind, dev.Name, state)
+	}
+
+	for _, dev := range s.USB.Devices {
+		if slices.Contains(dev.Classes, "hub") {
+			continue
+		}
+		line := fmt.Sprintf("%s (%s, %s)", usbName(dev), dev.Path, dev.Speed)
+		if dev.Degraded {
+			line += fmt.Sprintf(", below its %g Mbps", dev.MaxSpeedMbps)
+		}
+		if dev.PortSpeedMbps > dev.SpeedMbps {
+			line += fmt.Sprintf(", port supports %g Mbps", dev.PortSpeedMbps)
+		}
+		fmt.Fprintf(w, "USB\t%s\n", line)
+	}
+
+	gpuState := "none"
+	if s.GPU.Present {
+		gpuState = fmt.Sprintf("%s (%s)", strings.Join(s.GPU.Vendors, ", "), strings.Join(s.GPU.RenderNodes, ", "))
+	}
+	fmt.Fprintf(w, "GPU\t%s\n", gpuState)
+
+	if s.Load != nil {
+		fmt.Fprintf(w, "Load\tCPU %.1f%%, memory %.1f%%\n", s.Load.CPU.UsagePercent, s.Load.Memory.UsedPercent)
+		for _, d := range s.Load.Disks {
+			fmt.Fprintf(w, "Disk %s\t%s free\n", d.Path, formatBytes(d.FreeBytes))
+		}
+		for _, warning := range s.Load.Warnings {
+			fmt.Fprintf(w, "\twarning: %s\n", warning)
+		}
+	}
+
+	fmt.Fprintln(w, "\t")
+	for _, name := range sortedNames(snapshot.Capabilities.Details) {
+		result := snapshot.Capabilities.Details[name]
+		if result.Available {
+			fmt.Fprintf(w, "%s\tyes\n", name)
+			continue
+		}
+		reasons := make([]string, 0, len(result.Unmet))
+		for _, unmet := range result.Unmet {
+			reasons = append(reasons, unmet.Reason)
+		}
+		fmt.Fprintf(w, "%s\tno: %s\n", name, strings.Join(reasons, "; "))
+	}
+
+	for _, name := range sortedNames(s.Probes) {
+		if probe := s.Probes[name]; probe.Error != "" {
+			fmt.Fprintf(w, "probe %s\t%s\n", name, probe.Error)
+		}
+	}
+
+	_ = w.Flush()
+}
+
+func validateCommand(configPath string, args []string) int {
+	fs, path := commandFlags("config validate", configPath)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	resolved := config.Resolve(*path)
+	if resolved == "" {
+		fmt.Println("no config file found, the defaults would be used")
+		return 0
+	}
+
+	data, err := os.ReadFile(resolved)
+	if err == nil {
+		_, err = config.Parse(data)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", resolved, err)
+		return 1
+	}
+
+	fmt.Printf("%s: ok\n", resolved)
+	return 0
+}
+
+func printDefaultsCommand() int {
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(config.Default()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// describeSessions names the graphical sessions, or the sockets if there
+// are none, e.g. "anna (wayland, active)".
+func describeSessions(d display.Status) string {
+	var parts []string
+	for _, session := range d.Sessions {
+		if !session.Graphical() {
+			continue
+		}
+		state := session.Type
+		if session.Active {
+			state += ", active"
+		}
+		if session.Remote {
+			state += ", remote"
+		}
+		parts = append(parts, fmt.Sprintf("%s (%s)", session.User, state))
+	}
+	if len(parts) == 0 {
+		for _, socket := range d.Sockets {
+			parts = append(parts, fmt.Sprintf("%s %s", socket.Type, socket.Display))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeMonitor reads e.g. "HDMI-A-1: DELL U2720Q, 3840x2160@60".
+func describeMonitor(m display.Monitor) string {
+	desc := m.Connector + ":"
+	if m.Name != "" {
+		desc += " " + m.Name + ","
+	} else if m.Manufacturer != "" {
+		desc += " " + m.Manufacturer + ","
+	}
+
+	switch {
+	case m.PreferredMode != "":
+		desc += " " + m.PreferredMode
+	case len(m.Modes) > 0:
+		desc += " " + m.Modes[0]
+	default:
+		desc += " no modes"
+	}
+	return desc
+}
+
+// usbName prefers the name the device reports over the usb.ids one, which
+// is often just the chip vendor's.
+func usbName(dev usb.Device) string {
+	vendor, product := dev.Manufacturer, dev.Product
+	if vendor == "" {
+		vendor = dev.Vendor
+	}
+	if product == "" {
+		product = dev.Model
+	}
+	if product == "" {
+		product = dev.VendorID + ":" + dev.ProductID
+	}
+	if vendor == "" || strings.HasPrefix(product, vendor) {
+		return product
+	}
+	return vendor + " " + product
+}
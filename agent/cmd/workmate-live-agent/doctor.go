@@ -0,0 +1,414 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"kit.workmate/live-agent/internal/config"
+	"kit.workmate/live-agent/internal/health"
+)
+
+// Doctor result levels
+const (
+	levelPass = "PASS"
+	levelWarn = "WARN"
+	levelFail = "FAIL"
+)
+
+type finding struct {
+	level  string
+	name   string
+	detail string
+	fix    string
+}
+
+func pass(name, detail string) finding { return finding{levelPass, name, detail, ""} }
+
+func warn(name, detail, fix string) finding { return finding{levelWarn, name, detail, fix} }
+
+func fail(name, detail, fix string) finding { return finding{levelFail, name, detail, fix} }
+
+// doctorCommand runs every probe once, whatever health.checks says, and
+// explains what is wrong. It exits 1 if anything failed.
+func doctorCommand(configPath string, args []string) int {
+	flags, path := commandFlags("doctor", configPath)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var findings []finding
+
+	resolved := config.Resolve(*path)
+	cfg, err := config.Load(resolved)
+	if err == nil && resolved != "" {
+		// Load falls back to the defaults for a missing file, but a path
+		// given with -config has to exist, as for config validate
+		_, err = os.Stat(resolved)
+	}
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		findings = append(findings, fail("config", err.Error(), "check the -config path, the checks below use the defaults"))
+		cfg = config.Default()
+	case err != nil:
+		findings = append(findings, fail("config", err.Error(), "fix the config file, the checks below use the defaults"))
+		cfg = config.Default()
+	case resolved == "":
+		findings = append(findings, pass("config", "no config file, using the defaults"))
+	default:
+		findings = append(findings, pass("config", resolved))
+	}
+
+	healthCfg := cfg.Health
+	healthCfg.Checks = config.ChecksConfig{
+		GPU:          true,
+		Audio:        true,
+		Video:        true,
+		OBS:          true,
+		Load:         true,
+		Display:      true,
+		USB:          true,
+		AudioBackend: cfg.Health.Checks.AudioBackend,
+	}
+	snapshot, _ := collectOnce(healthCfg)
+	status := snapshot.Status
+
+	findings = append(findings, checkProbes(status)...)
+	findings = append(findings, checkVideo(status)...)
+	findings = append(findings, checkExpected(status)...)
+	findings = append(findings, checkAudio(status))
+	findings = append(findings, checkGPU(status)...)
+	findings = append(findings, checkDisplay(status)...)
+	findings = append(findings, checkUSB(status)...)
+	findings = append(findings, checkOBS(status, cfg.OBS)...)
+	findings = append(findings, checkLoad(status)...)
+	findings = append(findings, checkCapabilities(snapshot.Capabilities)...)
+	findings = append(findings, checkAPI(cfg.Server))
+	if cfg.Portal.Enabled {
+		findings = append(findings, checkPortal(cfg.Portal))
+	}
+
+	printFindings(os.Stdout, findings)
+
+	if slices.ContainsFunc(findings, func(f finding) bool { return f.level == levelFail }) {
+		return 1
+	}
+	return 0
+}
+
+func printFindings(out io.Writer, findings []finding) {
+	counts := map[string]int{}
+	for _, f := range findings {
+		counts[f.level]++
+		fmt.Fprintf(out, "[%s] %-22s %s\n", f.level, f.name, f.detail)
+		if f.fix != "" {
+			fmt.Fprintf(out, "       %-22s fix: %s\n", "", f.fix)
+		}
+	}
+	fmt.Fprintf(out, "\n%d passed, %d warnings, %d failed\n", counts[levelPass], counts[levelWarn], counts[levelFail])
+}
+
+func checkProbes(status *health.Status) []finding {
+	var findings []finding
+	for _, name := range sortedNames(status.Probes) {
+		if probe := status.Probes[name]; probe.Error != "" {
+			findings = append(findings, fail("probe "+name, probe.Error,
+				"a device or /proc, /sys read hangs or fails; check dmesg, or raise health.probes.timeouts."+name))
+		}
+	}
+	return findings
+}
+
+func checkVideo(status *health.Status) []finding {
+	video := status.Video
+	if video.DeviceCount == 0 {
+		return []finding{fail("video", "no video device found",
+			"connect the camera or capture card and check that it shows up in lsusb/lspci and dmesg")}
+	}
+
+	var findings []finding
+	capture := 0
+	for _, dev := range video.Details {
+		if dev.Error != "" {
+			fix := "check the device with v4l2-ctl --all -d " + dev.Path
+			if strings.Contains(dev.Error, "permission") {
+				fix = "add the agent's user to the video group"
+			}
+			findings = append(findings, warn("video "+dev.Path, dev.Error, fix))
+		}
+		if dev.Capture {
+			capture++
+		}
+	}
+
+	if capture == 0 {
+		return append(findings, warn("video", fmt.Sprintf("%d device(s), none can capture", video.DeviceCount),
+			"the nodes are metadata or output only; check the capture card's driver"))
+	}
+	return append(findings, pass("video", fmt.Sprintf("%d capture device(s)", capture)))
+}
+
+func checkAudio(status *health.Status) finding {
+	audio := status.Audio
+	switch {
+	case audio.Backend == "" || audio.Backend == "none":
+		return fail("audio", "no audio backend running",
+			"start PipeWire or PulseAudio in the user session (systemctl --user start pipewire)")
+	case !audio.Ready:
+		return warn("audio", audio.Backend+" has no input", "connect a microphone or a capture card with audio")
+	}
+	return pass("audio", fmt.Sprintf("%s, %d source(s)", audio.Backend, len(audio.Sources)))
+}
+
+func checkGPU(status *health.Status) []finding {
+	gpu := status.GPU
+	if !gpu.Present {
+		return []finding{warn("gpu", "no render node in /dev/dri, OBS will encode in software",
+			"install the GPU driver (and firmware) so /dev/dri/renderD* appears")}
+	}
+
+	var findings []finding
+	for _, node := range gpu.RenderNodes {
+		f, err := os.OpenFile(node, os.O_RDWR, 0)
+		if err != nil {
+			if errors.Is(err, fs.ErrPermission) {
+				findings = append(findings, warn("gpu "+node, "no access, hardware encoding will fail",
+					"add the agent's and OBS's user to the render group"))
+			}
+			continue
+		}
+		_ = f.Close()
+	}
+
+	return append(findings, pass("gpu", strings.Join(gpu.Vendors, ", ")+" ("+strings.Join(gpu.RenderNodes, ", ")+")"))
+}
+
+func checkDisplay(status *health.Status) []finding {
+	d := status.Display
+	var findings []finding
+
+	switch {
+	case !status.Headless && !d.Available:
+		findings = append(findings, pass("display", "DISPLAY or WAYLAND_DISPLAY is set"))
+	case !d.Available:
+		return []finding{warn("display", "no graphical session and no Wayland or X11 socket found",
+			"log in to a desktop session, or enable auto-login for the streaming user")}
+	default:
+		findings = append(findings, pass("display", describeSessions(d)))
+	}
+
+	if len(d.Monitors) == 0 {
+		return append(findings, warn("monitors", "no monitor connected",
+			"OBS preview and fullscreen projectors need a monitor or an HDMI dummy plug"))
+	}
+	for _, m := range d.Monitors {
+		if !m.Enabled {
+			findings = append(findings, warn("monitor "+m.Connector, describeMonitor(m)+" connected but disabled",
+				"enable the output in the desktop's display settings"))
+			continue
+		}
+		findings = append(findings, pass("monitor "+m.Connector, describeMonitor(m)))
+	}
+	return findings
+}
+
+// checkExpected fails for every device of the manifest that isn't
+// plugged in.
+func checkExpected(status *health.Status) []finding {
+	var findings []finding
+	for _, dev := range status.ExpectedDevices {
+		name := "expected " + dev.Name
+		if !dev.Present {
+			findings = append(findings, fail(name, "no "+dev.Kind+" device matches",
+				"plug it in, or compare the identifiers with the ids in `status --json`"))
+			continue
+		}
+		findings = append(findings, pass(name, strings.Join(dev.Paths, ", ")))
+	}
+	return findings
+}
+
+// checkUSB only reports devices that negotiated less than they support;
+// the inventory itself is in `status`.
+func checkUSB(status *health.Status) []finding {
+	var findings []finding
+	for _, dev := range status.USB.Devices {
+		if dev.Degraded {
+			fix := "plug it into a USB 3 port directly, without a USB 2 hub or cable in between"
+			if dev.PortSpeedMbps >= dev.MaxSpeedMbps {
+				fix = "the port supports the full speed, replace the cable or the hub in between"
+			}
+			findings = append(findings, warn("usb "+dev.Path,
+				fmt.Sprintf("%s supports %g Mbps but runs at %s (%g Mbps)", usbName(dev), dev.MaxSpeedMbps, dev.Speed, dev.SpeedMbps),
+				fix))
+		}
+	}
+	if len(findings) == 0 {
+		findings = append(findings, pass("usb", fmt.Sprintf("%d devices, none below their speed", len(status.USB.Devices))))
+	}
+	return findings
+}
+
+func checkOBS(status *health.Status, cfg config.OBSConfig) []finding {
+	obs := status.OBS
+	if !obs.Running {
+		if len(cfg.Command) > 0 {
+			if _, err := exec.LookPath(cfg.Command[0]); err != nil {
+				return []finding{warn("obs", "not running and "+cfg.Command[0]+" not found",
+					"install OBS Studio or set obs.command to how it is started here")}
+			}
+		}
+		return []finding{warn("obs", "not running", "start OBS, or let the agent do it with obs.supervise")}
+	}
+
+	detail := "running"
+	if obs.Process != nil {
+		detail = fmt.Sprintf("running (pid %d, %s)", obs.Process.PID, obs.Process.InstallType)
+	}
+	findings := []finding{pass("obs", detail)}
+
+	switch {
+	case obs.WebSocket == nil:
+		findings = append(findings, warn("obs websocket", "no obs-websocket config found",
+			"OBS 28 or newer includes it; open Tools → WebSocket Server Settings once"))
+	case !obs.WebSocket.Enabled:
+		findings = append(findings, warn("obs websocket", "disabled",
+			"enable it under Tools → WebSocket Server Settings, the portal needs it for remote control"))
+	default:
+		findings = append(findings, pass("obs websocket", fmt.Sprintf("enabled on port %d", obs.WebSocket.Port)))
+	}
+
+	return findings
+}
+
+func checkLoad(status *health.Status) []finding {
+	l := status.Load
+	if l == nil {
+		return nil
+	}
+
+	var findings []finding
+	for _, warning := range l.Warnings {
+		findings = append(findings, warn("load", warning,
+			"close other programs or free disk space; the limits are in health.load.thresholds"))
+	}
+	for _, d := range l.Disks {
+		if d.Error != "" {
+			findings = append(findings, warn("disk "+d.Path, d.Error, "check health.load.disk_paths"))
+		}
+	}
+
+	if len(findings) == 0 {
+		findings = append(findings, pass("load", fmt.Sprintf("CPU %.1f%%, memory %.1f%%", l.CPU.UsagePercent, l.Memory.UsedPercent)))
+	}
+	return findings
+}
+
+// checkCapabilities fails for the built-in capabilities, which the stream
+// box exists for, and only warns for configured ones.
+func checkCapabilities(caps health.Capabilities) []finding {
+	builtin := map[string]bool{}
+	for _, rule := range config.DefaultCapabilityRules() {
+		builtin[rule.Name] = true
+	}
+
+	var findings []finding
+	for _, name := range sortedNames(caps.Details) {
+		result := caps.Details[name]
+		if result.Available {
+			findings = append(findings, pass(name, "available"))
+			continue
+		}
+
+		reasons := make([]string, 0, len(result.Unmet))
+		for _, unmet := range result.Unmet {
+			reasons = append(reasons, unmet.Reason)
+		}
+		detail := "unavailable: " + strings.Join(reasons, "; ")
+		fix := capabilityFix(caps, result.Unmet)
+
+		if builtin[name] {
+			findings = append(findings, fail(name, detail, fix))
+		} else {
+			findings = append(findings, warn(name, detail, fix))
+		}
+	}
+	return findings
+}
+
+// capabilityFixes are the fixes for conditions on a status section, by the
+// first segment of the field.
+var capabilityFixes = map[string]string{
+	"video":    "connect the camera or capture card",
+	"audio":    "fix the audio backend, see the audio check",
+	"headless": "log in to a graphical session on the stream box",
+	"display":  "log in to a graphical session and check the monitor cables",
+	"obs":      "start OBS",
+	"gpu":      "check the GPU driver, see the gpu check",
+	"usb":      "plug in the USB device, see the usb checks",
+	"load":     "close other programs or free disk space",
+}
+
+// capabilityFix joins the fixes for the unmet conditions, in their order.
+func capabilityFix(caps health.Capabilities, unmet []health.UnmetCondition) string {
+	var fixes []string
+	for _, u := range unmet {
+		var fix string
+		if _, ok := caps.Details[u.Condition]; ok {
+			fix = "make " + u.Condition + " available first"
+		} else {
+			field, _, _ := strings.Cut(u.Condition, " ")
+			section := strings.FieldsFunc(field, func(r rune) bool { return r == '.' || r == '[' })
+			if len(section) > 0 {
+				fix = capabilityFixes[section[0]]
+			}
+			if fix == "" {
+				fix = "check " + field + " in the status --json output"
+			}
+		}
+		if !slices.Contains(fixes, fix) {
+			fixes = append(fixes, fix)
+		}
+	}
+	return strings.Join(fixes, "; ")
+}
+
+func checkAPI(cfg config.ServerConfig) finding {
+	conn, err := net.DialTimeout("tcp", cfg.Addr(), 2*time.Second)
+	if err != nil {
+		return warn("api", "nothing listening on "+cfg.Addr(), "start the agent without a command (or its service)")
+	}
+	_ = conn.Close()
+	return pass("api", "listening on "+cfg.Addr())
+}
+
+func checkPortal(cfg config.PortalConfig) finding {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || u.Host == "" {
+		return fail("portal", "invalid URL "+cfg.URL, "set portal.url to e.g. https://portal.example.org")
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		host = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	conn, err := net.DialTimeout("tcp", host, cfg.Timeout)
+	if err != nil {
+		return warn("portal", err.Error(), "check the network and portal.url; the agent keeps retrying")
+	}
+	_ = conn.Close()
+	return pass("portal", "reachable at "+host)
+}
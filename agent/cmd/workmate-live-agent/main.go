@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"log"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
@@ -11,36 +13,135 @@ import (
 	"kit.workmate/live-agent/internal/api"
 	"kit.workmate/live-agent/internal/config"
 	"kit.workmate/live-agent/internal/health"
+	"kit.workmate/live-agent/internal/recordings"
+	"kit.workmate/live-agent/internal/reload"
+	"kit.workmate/live-agent/internal/supervisor"
+	"kit.workmate/live-agent/internal/systemd"
 )
 
 func main() {
 	configPath := flag.String("config", "", "path to config file")
+	flag.Usage = usage
 	flag.Parse()
 
+	if flag.NArg() > 0 {
+		os.Exit(runCommand(*configPath, flag.Args()))
+	}
+
 	// Load configuration
-	cfg, err := config.Load(*configPath)
+	path := config.Resolve(*configPath)
+	cfg, err := config.Load(path)
 	if err != nil {
 		log.Fatalf("failed to load config: %v", err)
 	}
 
 	// Initialize components with config
-	cache := health.NewCache()
-	poller := health.NewPoller(cache, cfg.Health.PollingInterval, cfg.Health.Checks)
+	history := health.NewHistory(cfg.Health.History.Size, cfg.Health.History.MaxAge)
+	cache := health.NewCache(history)
+	cache.SetRules(cfg.Health.CapabilityRules())
+	events := health.NewEventLog(cfg.Health.Hotplug.LogSize)
+	poller := health.NewPoller(cache, health.NewCollector(cfg.Health), cfg.Health.PollingInterval)
+	if cfg.Health.Hotplug.Enabled {
+		if err := poller.EnableHotplug(events, cfg.Health.Hotplug.FallbackInterval); err != nil {
+			log.Printf("hotplug detection unavailable, polling every %s: %v", cfg.Health.PollingInterval, err)
+		}
+	}
 	poller.Start()
 
-	handler := api.Routes(cache)
-	server := api.NewWithConfig(cfg.Server.Addr(), handler, cfg.Server.Timeouts)
-	server.Start()
+	warnUnauthenticated(cfg.Server)
+
+	rt := &runtime{
+		boot:       cfg,
+		cache:      cache,
+		poller:     poller,
+		auth:       api.NewAuth(cfg.Server),
+		supervisor: supervisor.New(cfg.OBS),
+		recordings: recordings.New(cfg.Recordings),
+	}
+	reloader := reload.New(path, cfg, rt.apply)
+
+	handler := api.Routes(cache, events, rt.auth, reloader, rt.supervisor, rt.recordings)
+	rt.server = api.NewWithConfig(cfg.Server.Addr(), handler, cfg.Server.Timeouts)
+	if cfg.Server.TLS.Enabled {
+		if err := rt.server.EnableTLS(cfg.Server.TLS); err != nil {
+			log.Fatalf("failed to set up TLS: %v", err)
+		}
+	}
+
+	listeners, err := systemd.Listeners()
+	if err != nil {
+		log.Fatalf("socket activation: %v", err)
+	}
+	if len(listeners) > 0 {
+		for _, ln := range listeners[1:] {
+			log.Printf("socket activation: ignoring %s, only the first socket is served", ln.Addr())
+			_ = ln.Close()
+		}
+		rt.server.StartListener(listeners[0])
+	} else if err := rt.server.Start(); err != nil {
+		log.Fatalf("failed to start API server: %v", err)
+	}
+
+	done := make(chan struct{})
+	go notifyLoop(poller, cache, done)
 
+	rt.startReporter(cfg.Portal)
+
+	if cfg.Reload.Watch {
+		reloader.Watch(cfg.Reload.Interval)
+	}
+
+	// Wait for a signal other than a reload, or the API failing. A failed
+	// API ends the watchdog pings with the process and exits non-zero, so
+	// systemd restarts the agent.
 	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
-	<-stop
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	var serveErr error
+wait:
+	for {
+		select {
+		case sig := <-stop:
+			if sig != syscall.SIGHUP {
+				break wait
+			}
+			if err := reloader.Reload(); errors.Is(err, reload.ErrNoConfigFile) {
+				log.Printf("config: %v", err)
+			}
+		case serveErr = <-rt.server.Err():
+			log.Printf("HTTP server error: %v", serveErr)
+			break wait
+		}
+	}
 
 	log.Println("stopping agent")
+	_ = systemd.Notify("STOPPING=1")
+	close(done)
 
+	reloader.Stop()
+	rt.stopReporter()
+	rt.supervisor.Close()
 	poller.Stop()
 
-	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.Timeouts.Shutdown)
+	ctx, cancel := context.WithTimeout(context.Background(), reloader.Config().Server.Timeouts.Shutdown)
 	defer cancel()
-	_ = server.Shutdown(ctx)
+	_ = rt.server.Shutdown(ctx)
+
+	if serveErr != nil {
+		cancel()
+		os.Exit(1)
+	}
+}
+
+func warnUnauthenticated(cfg config.ServerConfig) {
+	if !cfg.Auth.Enabled && !isLoopbackAddress(cfg.Address) {
+		log.Printf("warning: API on %s without auth, anyone on the network can read the status", cfg.Address)
+	}
+}
+
+func isLoopbackAddress(address string) bool {
+	if address == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(address)
+	return ip != nil && ip.IsLoopback()
 }
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"kit.workmate/live-agent/internal/health"
+	"kit.workmate/live-agent/internal/systemd"
+)
+
+// notifyLoop tells systemd the agent is ready once the first status is
+// collected, keeps the unit's STATUS= up to date and pings the watchdog
+// as long as the poller keeps collecting. A hung poller then gets the
+// agent restarted.
+func notifyLoop(poller *health.Poller, cache *health.Cache, done <-chan struct{}) {
+	select {
+	case <-poller.Ready():
+	case <-done:
+		return
+	}
+
+	last := capabilitySummary(cache.Capabilities())
+	if err := systemd.Notify("READY=1\nSTATUS=" + last); err != nil {
+		log.Printf("systemd: %v", err)
+	}
+
+	watchdog := systemd.WatchdogInterval()
+	every := 5 * time.Second
+	if watchdog > 0 {
+		every = min(every, watchdog/2)
+	}
+
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+
+		healthy := poller.Healthy()
+		summary := capabilitySummary(cache.Capabilities())
+		if !healthy {
+			summary = "status collection stalled"
+		}
+
+		var lines []string
+		if summary != last {
+			lines = append(lines, "STATUS="+summary)
+			last = summary
+		}
+		if watchdog > 0 && healthy {
+			lines = append(lines, "WATCHDOG=1")
+		}
+		if len(lines) > 0 {
+			_ = systemd.Notify(strings.Join(lines, "\n"))
+		}
+	}
+}
+
+// capabilitySummary fits the capabilities on the status line of
+// systemctl status, e.g. "can_stream: no (OBS is not running)".
+func capabilitySummary(caps health.Capabilities) string {
+	var parts []string
+	for _, name := range sortedNames(caps.Details) {
+		result := caps.Details[name]
+		switch {
+		case result.Available:
+			parts = append(parts, name+": yes")
+		case len(result.Unmet) > 0:
+			parts = append(parts, fmt.Sprintf("%s: no (%s)", name, result.Unmet[0].Reason))
+		default:
+			parts = append(parts, name+": no")
+		}
+	}
+
+	if len(caps.Warnings) > 0 {
+		parts = append(parts, "load: "+strings.Join(caps.Warnings, ", "))
+	}
+	return strings.Join(parts, ", ")
+}
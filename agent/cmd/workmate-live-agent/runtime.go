@@ -0,0 +1,104 @@
+package main
+
+import (
+	"reflect"
+	"sync"
+
+	"kit.workmate/live-agent/internal/api"
+	"kit.workmate/live-agent/internal/config"
+	"kit.workmate/live-agent/internal/health"
+	"kit.workmate/live-agent/internal/portal"
+	"kit.workmate/live-agent/internal/recordings"
+	"kit.workmate/live-agent/internal/supervisor"
+)
+
+// runtime holds the components a config reload has to reach.
+type runtime struct {
+	// boot is the config the agent started with, for settings that
+	// can't change without a restart
+	boot *config.Config
+
+	cache      *health.Cache
+	poller     *health.Poller
+	auth       *api.Auth
+	server     *api.Server
+	supervisor *supervisor.Supervisor
+	recordings *recordings.Library
+
+	mu       sync.Mutex
+	reporter *portal.Reporter
+}
+
+// apply switches the running agent to cfg. Only the listener can fail,
+// so it goes first and nothing else changes if it does.
+func (rt *runtime) apply(old, cfg *config.Config) ([]string, error) {
+	// A socket-activated listener belongs to systemd and stays as it is
+	if !rt.server.Inherited() && listenerChanged(old.Server, cfg.Server) {
+		if err := rt.server.Rebind(cfg.Server); err != nil {
+			return nil, err
+		}
+	}
+	rt.auth.Update(cfg.Server)
+	warnUnauthenticated(cfg.Server)
+
+	if !reflect.DeepEqual(old.Health, cfg.Health) {
+		interval := cfg.Health.PollingInterval
+		if rt.poller.HotplugEnabled() {
+			interval = cfg.Health.Hotplug.FallbackInterval
+		}
+		rt.cache.SetRules(cfg.Health.CapabilityRules())
+		rt.poller.Reconfigure(rt.poller.Collector().Reconfigured(cfg.Health), interval)
+	}
+
+	rt.supervisor.Update(cfg.OBS)
+	rt.recordings.Update(cfg.Recordings)
+
+	if !reflect.DeepEqual(old.Portal, cfg.Portal) {
+		rt.stopReporter()
+		rt.startReporter(cfg.Portal)
+	}
+
+	var restartRequired []string
+	boot := rt.boot
+	if rt.server.Inherited() && listenerChanged(boot.Server, cfg.Server) {
+		restartRequired = append(restartRequired, "server")
+	}
+	if boot.Health.Hotplug.Enabled != cfg.Health.Hotplug.Enabled || boot.Health.Hotplug.LogSize != cfg.Health.Hotplug.LogSize {
+		restartRequired = append(restartRequired, "health.hotplug")
+	}
+	if boot.Health.History != cfg.Health.History {
+		restartRequired = append(restartRequired, "health.history")
+	}
+	if boot.Reload != cfg.Reload {
+		restartRequired = append(restartRequired, "reload")
+	}
+
+	return restartRequired, nil
+}
+
+func (rt *runtime) startReporter(cfg config.PortalConfig) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if !cfg.Enabled {
+		return
+	}
+	rt.reporter = portal.NewReporter(cfg, rt.cache, api.NewInfo())
+	rt.reporter.Start()
+}
+
+func (rt *runtime) stopReporter() {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if rt.reporter != nil {
+		rt.reporter.Stop()
+		rt.reporter = nil
+	}
+}
+
+func listenerChanged(old, cfg config.ServerConfig) bool {
+	return old.Addr() != cfg.Addr() ||
+		old.Timeouts != cfg.Timeouts ||
+		!reflect.DeepEqual(old.TLS, cfg.TLS)
+}
@@ -2,4 +2,4 @@ module kit.workmate/live-agent
 
 go 1.25.5
 
-require gopkg.in/yaml.v3 v3.0.1 // indirect
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"kit.workmate/live-agent/internal/config"
+)
+
+// Auth checks bearer tokens and verified client certificates against the
+// configured scopes. The control scope includes read.
+type Auth struct {
+	mu         sync.RWMutex
+	enabled    bool
+	keys       []apiKey
+	certScopes []string
+}
+
+type apiKey struct {
+	name   string
+	hash   []byte
+	scopes []string
+}
+
+// NewAuth expects a validated server config.
+func NewAuth(cfg config.ServerConfig) *Auth {
+	a := &Auth{}
+	a.Update(cfg)
+	return a
+}
+
+// Update replaces keys and scopes, e.g. after a config reload.
+func (a *Auth) Update(cfg config.ServerConfig) {
+	var keys []apiKey
+	for _, key := range cfg.Auth.Keys {
+		hash, _ := hex.DecodeString(strings.TrimPrefix(key.Hash, "sha256:"))
+		keys = append(keys, apiKey{name: key.Name, hash: hash, scopes: key.Scopes})
+	}
+
+	var certScopes []string
+	if cfg.TLS.Enabled && cfg.TLS.ClientCAFile != "" {
+		certScopes = cfg.TLS.ClientScopes
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.enabled = cfg.Auth.Enabled
+	a.keys = keys
+	a.certScopes = certScopes
+}
+
+// Require wraps a handler so it only runs for clients with the scope.
+// Without auth, read is open and control is limited to local clients.
+func (a *Auth) Require(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		a.mu.RLock()
+		enabled := a.enabled
+		a.mu.RUnlock()
+
+		if !enabled {
+			if scope == config.ScopeControl && !isLoopback(r) {
+				http.Error(w, "only available to local clients unless auth is enabled", http.StatusForbidden)
+				return
+			}
+			next(w, r)
+			return
+		}
+
+		scopes, ok := a.authenticate(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="workmate-agent"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if !hasScope(scopes, scope) {
+			http.Error(w, "missing scope "+scope, http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// authenticate returns the scopes of the request's credentials. A bearer
+// token takes precedence over a client certificate.
+func (a *Auth) authenticate(r *http.Request) ([]string, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+		for _, key := range a.keys {
+			if subtle.ConstantTimeCompare(sum[:], key.hash) == 1 {
+				return key.scopes, true
+			}
+		}
+		return nil, false
+	}
+
+	// The TLS stack has already verified the chain against the client CA
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(a.certScopes) > 0 {
+		return a.certScopes, true
+	}
+
+	return nil, false
+}
+
+func hasScope(scopes []string, scope string) bool {
+	if slices.Contains(scopes, scope) {
+		return true
+	}
+	return scope == config.ScopeRead && slices.Contains(scopes, config.ScopeControl)
+}
+
+func isLoopback(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
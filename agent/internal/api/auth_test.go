@@ -0,0 +1,119 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"kit.workmate/live-agent/internal/config"
+)
+
+func keyConfig(name, key string, scopes ...string) config.APIKeyConfig {
+	sum := sha256.Sum256([]byte(key))
+	return config.APIKeyConfig{Name: name, Hash: "sha256:" + hex.EncodeToString(sum[:]), Scopes: scopes}
+}
+
+// verifiedCert is what the TLS stack leaves on a request whose client
+// certificate chained up to the client CA.
+var verifiedCert = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}
+
+func TestAuthRequire(t *testing.T) {
+	open := config.ServerConfig{}
+	withAuth := config.ServerConfig{
+		Auth: config.AuthConfig{Enabled: true, Keys: []config.APIKeyConfig{
+			keyConfig("prometheus", "read-key", config.ScopeRead),
+			keyConfig("portal", "control-key", config.ScopeControl),
+		}},
+		TLS: config.TLSConfig{Enabled: true, ClientCAFile: "ca.pem", ClientScopes: []string{config.ScopeControl}},
+	}
+
+	tests := []struct {
+		name   string
+		cfg    config.ServerConfig
+		scope  string
+		remote string
+		token  string
+		tls    *tls.ConnectionState
+		want   int
+	}{
+		{name: "no auth, remote read", cfg: open, scope: config.ScopeRead, want: http.StatusOK},
+		{name: "no auth, local control", cfg: open, scope: config.ScopeControl, remote: "127.0.0.1:40000", want: http.StatusOK},
+		{name: "no auth, local IPv6 control", cfg: open, scope: config.ScopeControl, remote: "[::1]:40000", want: http.StatusOK},
+		{name: "no auth, remote control", cfg: open, scope: config.ScopeControl, want: http.StatusForbidden},
+		{name: "missing token", cfg: withAuth, scope: config.ScopeRead, want: http.StatusUnauthorized},
+		{name: "missing token from loopback", cfg: withAuth, scope: config.ScopeRead, remote: "127.0.0.1:40000", want: http.StatusUnauthorized},
+		{name: "wrong token", cfg: withAuth, scope: config.ScopeRead, token: "guess", want: http.StatusUnauthorized},
+		{name: "read key, read route", cfg: withAuth, scope: config.ScopeRead, token: "read-key", want: http.StatusOK},
+		{name: "read key, control route", cfg: withAuth, scope: config.ScopeControl, token: "read-key", want: http.StatusForbidden},
+		{name: "control key, read route", cfg: withAuth, scope: config.ScopeRead, token: "control-key", want: http.StatusOK},
+		{name: "control key, control route", cfg: withAuth, scope: config.ScopeControl, token: "control-key", want: http.StatusOK},
+		{name: "client cert", cfg: withAuth, scope: config.ScopeControl, tls: verifiedCert, want: http.StatusOK},
+		{name: "unverified client cert", cfg: withAuth, scope: config.ScopeRead, tls: &tls.ConnectionState{}, want: http.StatusUnauthorized},
+		{name: "token wins over client cert", cfg: withAuth, scope: config.ScopeControl, token: "read-key", tls: verifiedCert, want: http.StatusForbidden},
+		{name: "wrong token with client cert", cfg: withAuth, scope: config.ScopeRead, token: "guess", tls: verifiedCert, want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAuth(tt.cfg).Require(tt.scope, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/status", nil)
+			if tt.remote != "" {
+				r.RemoteAddr = tt.remote
+			}
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			r.TLS = tt.tls
+
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			challenge := w.Header().Get("WWW-Authenticate")
+			if (w.Code == http.StatusUnauthorized) != (challenge != "") {
+				t.Errorf("WWW-Authenticate = %q with status %d", challenge, w.Code)
+			}
+		})
+	}
+}
+
+func TestAuthUpdate(t *testing.T) {
+	cfg := config.ServerConfig{Auth: config.AuthConfig{Enabled: true, Keys: []config.APIKeyConfig{
+		keyConfig("portal", "old-key", config.ScopeControl),
+	}}}
+	auth := NewAuth(cfg)
+	handler := auth.Require(config.ScopeControl, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	call := func(token string) int {
+		r := httptest.NewRequest(http.MethodPost, "/obs/start", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w.Code
+	}
+
+	if code := call("old-key"); code != http.StatusOK {
+		t.Fatalf("old key before the update: status = %d, want %d", code, http.StatusOK)
+	}
+
+	cfg.Auth.Keys = []config.APIKeyConfig{keyConfig("portal", "new-key", config.ScopeControl)}
+	auth.Update(cfg)
+
+	if code := call("old-key"); code != http.StatusUnauthorized {
+		t.Errorf("old key after the update: status = %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := call("new-key"); code != http.StatusOK {
+		t.Errorf("new key after the update: status = %d, want %d", code, http.StatusOK)
+	}
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"kit.workmate/live-agent/internal/health"
+)
+
+// keepAliveInterval keeps proxies from closing idle event streams.
+const keepAliveInterval = 15 * time.Second
+
+// eventsHandler streams status changes as Server-Sent Events. New clients
+// get a snapshot first, reconnecting clients with Last-Event-ID get the
+// changes they missed (or a fresh snapshot if those are gone).
+func eventsHandler(cache *health.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rc := http.NewResponseController(w)
+
+		// The server write timeout would cut the stream after a few seconds
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+			http.Error(w, "streaming not supported", http.StatusInternalServerError)
+			return
+		}
+
+		var lastID uint64
+		if v := r.Header.Get("Last-Event-ID"); v != "" {
+			lastID, _ = strconv.ParseUint(v, 10, 64)
+		}
+
+		sub, snapshot := cache.Subscribe(lastID)
+		defer sub.Close()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		fmt.Fprint(w, "retry: 3000\n\n")
+
+		if !sub.Resumed {
+			if err := writeEvent(w, sub.Seq, health.EventSnapshot, snapshot); err != nil {
+				return
+			}
+		}
+		for _, c := range sub.Replay {
+			if err := writeEvent(w, c.ID, c.Type, c); err != nil {
+				return
+			}
+		}
+		_ = rc.Flush()
+
+		keepAlive := time.NewTicker(keepAliveInterval)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case c, ok := <-sub.C:
+				if !ok {
+					return // dropped as too slow, client will resume
+				}
+				if err := writeEvent(w, c.ID, c.Type, c); err != nil {
+					return
+				}
+
+			case <-keepAlive.C:
+				if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+					return
+				}
+
+			case <-r.Context().Done():
+				return
+			}
+
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, id uint64, typ string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, typ, payload)
+	return err
+}
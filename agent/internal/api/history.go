@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"kit.workmate/live-agent/internal/health"
+)
+
+type historyEntry struct {
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Status any       `json:"status"`
+}
+
+// historyHandler serves /status/history?since=&until=&fields=
+//
+// since and until accept RFC 3339 timestamps, Unix seconds or a duration
+// relative to now ("10m" means ten minutes ago). fields is a comma separated
+// list of JSON paths like "video.devices,obs" to keep responses small.
+func historyHandler(history *health.History) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		now := time.Now()
+
+		since, err := parseTime(q.Get("since"), now)
+		if err != nil {
+			http.Error(w, "invalid since: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		until, err := parseTime(q.Get("until"), now)
+		if err != nil {
+			http.Error(w, "invalid until: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var fields []string
+		if v := q.Get("fields"); v != "" {
+			fields = strings.Split(v, ",")
+		}
+
+		entries := history.Range(since, until)
+		result := make([]historyEntry, 0, len(entries))
+
+		for _, e := range entries {
+			var status any = e.Status
+			if len(fields) > 0 {
+				status, err = selectFields(e.Status, fields)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+			}
+			result = append(result, historyEntry{From: e.From, To: e.To, Status: status})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(result)
+	}
+}
+
+func parseTime(v string, now time.Time) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+
+	d, err := time.ParseDuration(strings.TrimPrefix(v, "-"))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC 3339 time, Unix seconds or duration, got %q", v)
+	}
+	return now.Add(-d), nil
+}
+
+// selectFields reduces a value to the given dotted JSON paths.
+func selectFields(v any, fields []string) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var full map[string]any
+	if err := json.Unmarshal(data, &full); err != nil {
+		return nil, err
+	}
+
+	result := map[string]any{}
+	for _, field := range fields {
+		parts := strings.Split(strings.TrimSpace(field), ".")
+
+		var cur any = full
+		for _, p := range parts {
+			m, ok := cur.(map[string]any)
+			if !ok {
+				cur = nil
+				break
+			}
+			cur = m[p]
+		}
+		if cur == nil {
+			continue
+		}
+
+		// Rebuild the nesting so "video.devices" stays under "video"
+		dst := result
+		for _, p := range parts[:len(parts)-1] {
+			next, ok := dst[p].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				dst[p] = next
+			}
+			dst = next
+		}
+		dst[parts[len(parts)-1]] = cur
+	}
+
+	return result, nil
+}
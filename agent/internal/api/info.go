@@ -1,6 +1,9 @@
 package api
 
-import "kit.workmate/live-agent/internal/system/specs"
+import (
+	"kit.workmate/live-agent/internal/buildinfo"
+	"kit.workmate/live-agent/internal/system/specs"
+)
 
 type Info struct {
 	Name      string      `json:"name"`
@@ -9,3 +12,14 @@ type Info struct {
 	BuildTime string      `json:"build_time"`
 	Specs     specs.Specs `json:"specs"`
 }
+
+// NewInfo describes this agent build and the machine it runs on.
+func NewInfo() Info {
+	return Info{
+		Name:      buildinfo.Name,
+		Version:   buildinfo.Version,
+		Commit:    buildinfo.Commit,
+		BuildTime: buildinfo.BuildTime,
+		Specs:     specs.Probe(),
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"kit.workmate/live-agent/internal/supervisor"
+	"kit.workmate/live-agent/internal/system/obs"
+)
+
+// OBSWebSocket is what a client needs to connect to obs-websocket.
+type OBSWebSocket struct {
+	Host         string `json:"host"`
+	Port         int    `json:"port"`
+	Enabled      bool   `json:"enabled"`
+	AuthRequired bool   `json:"auth_required"`
+	Password     string `json:"password,omitempty"`
+}
+
+// obsWebSocketHandler hands out the obs-websocket connection details,
+// including the password, so the portal doesn't need them configured.
+// Needs the control scope.
+func obsWebSocketHandler(w http.ResponseWriter, r *http.Request) {
+	cfg, err := obs.DiscoverWebSocket()
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, obs.ErrNoWebSocketConfig) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	// OBS runs on this machine, so it is reachable under the same
+	// host name the client used for the agent
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+
+	resp := OBSWebSocket{
+		Host:         host,
+		Port:         cfg.Port,
+		Enabled:      cfg.Enabled,
+		AuthRequired: cfg.AuthRequired,
+	}
+	if cfg.AuthRequired {
+		resp.Password = cfg.Password
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// obsSupervisorHandler runs a supervisor action and answers with the
+// supervisor status afterwards.
+func obsSupervisorHandler(sup *supervisor.Supervisor, action func() error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Stopping waits up to obs.stop_timeout, longer than the write timeout
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+		if err := action(); err != nil {
+			status := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, supervisor.ErrDisabled):
+				status = http.StatusNotFound
+			case errors.Is(err, supervisor.ErrAlreadyRunning), errors.Is(err, supervisor.ErrNotRunning):
+				status = http.StatusConflict
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(sup.Status())
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"time"
+
+	"kit.workmate/live-agent/internal/recordings"
+)
+
+func recordingDirsHandler(lib *recordings.Library) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(lib.Directories())
+	}
+}
+
+func recordingListHandler(lib *recordings.Library) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		listing, err := lib.List(r.PathValue("dir"))
+		if err != nil {
+			recordingError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(listing)
+	}
+}
+
+// recordingDownloadHandler serves a file with Range support, so broken
+// downloads of large recordings can be resumed.
+func recordingDownloadHandler(lib *recordings.Library) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f, err := lib.Open(r.PathValue("dir"), r.PathValue("file"))
+		if err != nil {
+			recordingError(w, err)
+			return
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil {
+			recordingError(w, err)
+			return
+		}
+
+		// Large files take longer than the server write timeout
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+		name := path.Base(r.PathValue("file"))
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+		http.ServeContent(w, r, name, info.ModTime(), f)
+	}
+}
+
+func recordingDeleteHandler(lib *recordings.Library) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := lib.Delete(r.PathValue("dir"), r.PathValue("file")); err != nil {
+			recordingError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func recordingError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, recordings.ErrUnknownDirectory), errors.Is(err, fs.ErrNotExist):
+		status = http.StatusNotFound
+	case errors.Is(err, recordings.ErrNotAFile), errors.Is(err, fs.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, fs.ErrPermission):
+		status = http.StatusForbidden
+	}
+	http.Error(w, err.Error(), status)
+}
@@ -3,16 +3,23 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 
-	"kit.workmate/live-agent/internal/buildinfo"
+	"kit.workmate/live-agent/internal/config"
 	"kit.workmate/live-agent/internal/health"
+	"kit.workmate/live-agent/internal/metrics"
+	"kit.workmate/live-agent/internal/recordings"
+	"kit.workmate/live-agent/internal/reload"
+	"kit.workmate/live-agent/internal/supervisor"
 	"kit.workmate/live-agent/internal/system/specs"
 )
 
-func Routes(cache *health.Cache) http.Handler {
+func Routes(cache *health.Cache, events *health.EventLog, auth *Auth, reloader *reload.Reloader, sup *supervisor.Supervisor, lib *recordings.Library) http.Handler {
 	mux := http.NewServeMux()
+	read := func(h http.HandlerFunc) http.HandlerFunc { return auth.Require(config.ScopeRead, h) }
+	control := func(h http.HandlerFunc) http.HandlerFunc { return auth.Require(config.ScopeControl, h) }
 
-	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/status", read(func(w http.ResponseWriter, r *http.Request) {
 		status := cache.Get()
 		if status == nil {
 			http.Error(w, "status not ready", http.StatusServiceUnavailable)
@@ -21,26 +28,61 @@ func Routes(cache *health.Cache) http.Handler {
 
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(status)
-	})
-
-	mux.HandleFunc("/info", func(w http.ResponseWriter, r *http.Request) {
-		info := Info{
-			Name:      buildinfo.Name,
-			Version:   buildinfo.Version,
-			Commit:    buildinfo.Commit,
-			BuildTime: buildinfo.BuildTime,
-			Specs:     specs.Probe(),
-		}
+	}))
+
+	mux.HandleFunc("/status/history", read(historyHandler(cache.History())))
 
+	mux.HandleFunc("/info", read(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(info)
-	})
-	mux.HandleFunc("/capabilities", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(NewInfo())
+	}))
+	mux.HandleFunc("/capabilities", read(func(w http.ResponseWriter, r *http.Request) {
 		caps := cache.Capabilities()
 
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(caps)
-	})
+	}))
+
+	mux.HandleFunc("/metrics", read(func(w http.ResponseWriter, r *http.Request) {
+		snapshot := metrics.Snapshot{
+			Status:       cache.Get(),
+			Capabilities: cache.Capabilities(),
+			Specs:        specs.Probe(),
+			UpdatedAt:    cache.UpdatedAt(),
+			Now:          time.Now(),
+		}
+
+		w.Header().Set("Content-Type", metrics.ContentType)
+		_ = metrics.Write(w, snapshot)
+	}))
+
+	mux.HandleFunc("/obs/websocket", control(obsWebSocketHandler))
+
+	mux.HandleFunc("/obs/supervisor", read(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(sup.Status())
+	}))
+	mux.HandleFunc("/obs/start", control(obsSupervisorHandler(sup, sup.Start)))
+	mux.HandleFunc("/obs/stop", control(obsSupervisorHandler(sup, sup.Stop)))
+	mux.HandleFunc("/obs/restart", control(obsSupervisorHandler(sup, sup.Restart)))
+
+	// Recordings can be large and private, so even listing needs control
+	mux.HandleFunc("GET /recordings", control(recordingDirsHandler(lib)))
+	mux.HandleFunc("GET /recordings/{dir}", control(recordingListHandler(lib)))
+	mux.HandleFunc("GET /recordings/{dir}/{file...}", control(recordingDownloadHandler(lib)))
+	mux.HandleFunc("DELETE /recordings/{dir}/{file...}", control(recordingDeleteHandler(lib)))
+
+	mux.HandleFunc("/events", read(eventsHandler(cache)))
+
+	mux.HandleFunc("/config", read(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(reloader.Status())
+	}))
+
+	mux.HandleFunc("/hotplug", read(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(events.List())
+	}))
 
 	return mux
 }
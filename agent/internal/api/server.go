@@ -2,49 +2,225 @@ package api
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"sync"
 	"time"
 
 	"kit.workmate/live-agent/internal/config"
 )
 
 type Server struct {
+	handler http.Handler
+
+	mu         sync.Mutex
 	httpServer *http.Server
+	tls        *config.TLSConfig
+	listener   net.Listener
+	drainAfter time.Duration
+	// inherited is set for a listener from socket activation
+	inherited bool
+
+	errs chan error
 }
 
 func New(addr string, handler http.Handler) *Server {
 	return &Server{
+		handler: handler,
 		httpServer: &http.Server{
 			Addr:         addr,
 			Handler:      handler,
 			ReadTimeout:  5 * time.Second,
 			WriteTimeout: 5 * time.Second,
 		},
+		drainAfter: 5 * time.Second,
+		errs:       make(chan error, 1),
 	}
 }
 
 func NewWithConfig(addr string, handler http.Handler, timeouts config.TimeoutConfig) *Server {
 	return &Server{
+		handler: handler,
 		httpServer: &http.Server{
 			Addr:         addr,
 			Handler:      handler,
 			ReadTimeout:  timeouts.Read,
 			WriteTimeout: timeouts.Write,
 		},
+		drainAfter: timeouts.Shutdown,
+		errs:       make(chan error, 1),
 	}
 }
 
-func (s *Server) Start() {
-	go func() {
-		log.Printf("API listening on %s", s.httpServer.Addr)
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("HTTP server error: %v", err)
+// EnableTLS serves HTTPS and, with a client CA, verifies client
+// certificates. Must be called before Start.
+func (s *Server) EnableTLS(cfg config.TLSConfig) error {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("reading client CA: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates in %s", cfg.ClientCAFile)
+		}
+
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		if cfg.RequireClientCert {
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
 		}
-	}()
+	}
+
+	// Fail at startup rather than on the first handshake
+	if _, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile); err != nil {
+		return fmt.Errorf("loading certificate: %w", err)
+	}
+
+	s.httpServer.TLSConfig = tlsConfig
+	s.tls = &cfg
+	return nil
+}
+
+// Start binds the listener, so an address in use is reported right away,
+// and serves in the background.
+func (s *Server) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ln, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+
+	s.listener = ln
+	go s.serve(s.httpServer, ln, s.tls)
+	return nil
+}
+
+// StartListener serves on a listener someone else bound, e.g. systemd
+// with socket activation. Such a listener can't be rebound.
+func (s *Server) StartListener(ln net.Listener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.httpServer.Addr = ln.Addr().String()
+	s.listener = ln
+	s.inherited = true
+	go s.serve(s.httpServer, ln, s.tls)
+}
+
+// Inherited reports whether the server runs on a socket-activated listener.
+func (s *Server) Inherited() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.inherited
+}
+
+// Rebind moves the API to the address, TLS and timeout settings in cfg.
+// Requests in flight on the old listener are drained in the background.
+// If the new address can't be bound, the old listener keeps serving.
+func (s *Server) Rebind(cfg config.ServerConfig) error {
+	next := NewWithConfig(cfg.Addr(), s.handler, cfg.Timeouts)
+	if cfg.TLS.Enabled {
+		if err := next.EnableTLS(cfg.TLS); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, oldTLS := s.httpServer, s.tls
+	// Binding 0.0.0.0 conflicts with 127.0.0.1 on the same port too
+	samePort := port(old.Addr) == port(next.httpServer.Addr)
+	if samePort {
+		// Free the port for the new listener
+		_ = s.listener.Close()
+	}
+
+	ln, err := net.Listen("tcp", next.httpServer.Addr)
+	if err != nil {
+		if samePort {
+			if ln, relistenErr := net.Listen("tcp", old.Addr); relistenErr == nil {
+				s.listener = ln
+				go s.serve(old, ln, oldTLS)
+			}
+		}
+		return err
+	}
+
+	if !samePort {
+		_ = s.listener.Close()
+	}
+	go drain(old, s.drainAfter)
+
+	s.httpServer, s.tls, s.listener, s.drainAfter = next.httpServer, next.tls, ln, next.drainAfter
+	go s.serve(s.httpServer, ln, s.tls)
+	return nil
+}
+
+func port(addr string) string {
+	_, p, _ := net.SplitHostPort(addr)
+	return p
+}
+
+// Err delivers the error that stopped the server from serving, e.g. an
+// accept on the listener failing for good.
+func (s *Server) Err() <-chan error {
+	return s.errs
+}
+
+func (s *Server) serve(srv *http.Server, ln net.Listener, tlsCfg *config.TLSConfig) {
+	if tlsCfg != nil {
+		log.Printf("API listening on %s (TLS)", srv.Addr)
+	} else {
+		log.Printf("API listening on %s", srv.Addr)
+	}
+
+	var err error
+	if tlsCfg != nil {
+		err = srv.ServeTLS(ln, tlsCfg.CertFile, tlsCfg.KeyFile)
+	} else {
+		err = srv.Serve(ln)
+	}
+
+	// A closed listener means we rebound or are shutting down
+	if err != nil && !errors.Is(err, http.ErrServerClosed) && !errors.Is(err, net.ErrClosed) {
+		select {
+		case s.errs <- err:
+		default:
+		}
+	}
+}
+
+// drain lets requests on a replaced server finish, then cuts off
+// long-lived streams like /events.
+func drain(srv *http.Server, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		_ = srv.Close()
+	}
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
 	log.Println("Shutting down API server")
-	return s.httpServer.Shutdown(ctx)
+
+	s.mu.Lock()
+	srv := s.httpServer
+	s.mu.Unlock()
+
+	return srv.Shutdown(ctx)
 }
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CapabilityRule defines a capability as a list of conditions that all
+// have to hold.
+type CapabilityRule struct {
+	Name       string      `yaml:"name"`
+	Conditions []Condition `yaml:"conditions"`
+}
+
+// Condition checks either a status field or another capability.
+//
+// Field is a dotted path into the /status JSON. Arrays match if any
+// element does, and a [key=value] segment picks elements, e.g.
+// "load.disks[path=/srv/recordings].free_bytes".
+type Condition struct {
+	Field      string `yaml:"field"`
+	Capability string `yaml:"capability"`
+	Op         string `yaml:"op"`
+	Value      any    `yaml:"value"`
+
+	// Reason is shown when the condition is not met
+	Reason string `yaml:"reason"`
+}
+
+// Condition operators. "contains" works on lists and strings, "exists"
+// only needs the field to be present and not empty.
+var ConditionOps = []string{"==", "!=", ">", ">=", "<", "<=", "contains", "exists"}
+
+// DefaultCapabilityRules are always defined. A configured rule with the
+// same name replaces the default one.
+func DefaultCapabilityRules() []CapabilityRule {
+	return []CapabilityRule{
+		{
+			Name: "can_video",
+			Conditions: []Condition{
+				{Field: "video.device_count", Op: ">=", Value: 1, Reason: "no video device found"},
+			},
+		},
+		{
+			Name: "can_audio",
+			Conditions: []Condition{
+				{Field: "audio.ready", Op: "==", Value: true, Reason: "audio backend not ready"},
+			},
+		},
+		{
+			Name: "can_stream",
+			Conditions: []Condition{
+				{Capability: "can_video", Reason: "no video input"},
+				{Capability: "can_audio", Reason: "no audio input"},
+				{Field: "headless", Op: "==", Value: false, Reason: "no graphical session or display server"},
+				{Field: "obs.running", Op: "==", Value: true, Reason: "OBS is not running"},
+			},
+		},
+	}
+}
+
+// CapabilityRules returns the defaults merged with the configured rules.
+func (h *HealthConfig) CapabilityRules() []CapabilityRule {
+	rules := DefaultCapabilityRules()
+
+	for _, rule := range h.Capabilities {
+		replaced := false
+		for i := range rules {
+			if rules[i].Name == rule.Name {
+				rules[i] = rule
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
+
+func validateCapabilityRules(rules []CapabilityRule) error {
+	defined := map[string]bool{}
+
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return errors.New("capability without name")
+		}
+		if defined[rule.Name] {
+			return fmt.Errorf("capability %q defined twice", rule.Name)
+		}
+		if len(rule.Conditions) == 0 {
+			return fmt.Errorf("capability %q has no conditions", rule.Name)
+		}
+
+		for i, c := range rule.Conditions {
+			if err := c.validate(defined); err != nil {
+				return fmt.Errorf("capability %q condition %d: %w", rule.Name, i+1, err)
+			}
+		}
+
+		defined[rule.Name] = true
+	}
+
+	return nil
+}
+
+func (c *Condition) validate(defined map[string]bool) error {
+	if (c.Field == "") == (c.Capability == "") {
+		return errors.New("needs either field or capability")
+	}
+
+	if c.Capability != "" {
+		if !defined[c.Capability] {
+			return fmt.Errorf("capability %q must be defined before it is used", c.Capability)
+		}
+		return nil
+	}
+
+	if strings.Count(c.Field, "[") != strings.Count(c.Field, "]") {
+		return fmt.Errorf("unbalanced brackets in field %q", c.Field)
+	}
+
+	known := false
+	for _, op := range ConditionOps {
+		known = known || op == c.Op
+	}
+	if !known {
+		return fmt.Errorf("unknown op %q, use one of %s", c.Op, strings.Join(ConditionOps, " "))
+	}
+
+	if c.Op != "exists" && c.Value == nil {
+		return fmt.Errorf("op %q needs a value", c.Op)
+	}
+
+	return nil
+}
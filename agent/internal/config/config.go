@@ -15,12 +15,51 @@ type Config struct {
 	Server ServerConfig `yaml:"server"`
 	Health HealthConfig `yaml:"health"`
 	Portal PortalConfig `yaml:"portal"`
+	Reload ReloadConfig `yaml:"reload"`
+	OBS    OBSConfig    `yaml:"obs"`
+
+	Recordings RecordingsConfig `yaml:"recordings"`
 }
 
 type ServerConfig struct {
 	Address  string        `yaml:"address"`
 	Port     int           `yaml:"port"`
 	Timeouts TimeoutConfig `yaml:"timeouts"`
+	Auth     AuthConfig    `yaml:"auth"`
+	TLS      TLSConfig     `yaml:"tls"`
+}
+
+// API scopes. Read covers the status endpoints, control everything that
+// changes state or hands out secrets.
+const (
+	ScopeRead    = "read"
+	ScopeControl = "control"
+)
+
+// AuthConfig protects the API with bearer tokens and/or client certificates.
+type AuthConfig struct {
+	Enabled bool           `yaml:"enabled"`
+	Keys    []APIKeyConfig `yaml:"keys"`
+}
+
+// APIKeyConfig is a static API key. Only its hash is stored, as
+// "sha256:<hex>" (e.g. from `printf %s "$KEY" | sha256sum`).
+type APIKeyConfig struct {
+	Name   string   `yaml:"name"`
+	Hash   string   `yaml:"hash"`
+	Scopes []string `yaml:"scopes"`
+}
+
+type TLSConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+
+	// ClientCAFile enables mutual TLS. Clients presenting a certificate
+	// signed by this CA are authenticated with ClientScopes.
+	ClientCAFile      string   `yaml:"client_ca_file"`
+	RequireClientCert bool     `yaml:"require_client_cert"`
+	ClientScopes      []string `yaml:"client_scopes"`
 }
 
 type TimeoutConfig struct {
@@ -32,13 +71,98 @@ type TimeoutConfig struct {
 type HealthConfig struct {
 	PollingInterval time.Duration `yaml:"polling_interval"`
 	Checks          ChecksConfig  `yaml:"checks"`
+	Hotplug         HotplugConfig `yaml:"hotplug"`
+	History         HistoryConfig `yaml:"history"`
+	Load            LoadConfig    `yaml:"load"`
+	Probes          ProbesConfig  `yaml:"probes"`
+
+	// Capabilities adds rules or replaces the default ones by name
+	Capabilities []CapabilityRule `yaml:"capabilities"`
+
+	// ExpectedDevices are reported as present or missing in /status
+	ExpectedDevices []ExpectedDevice `yaml:"expected_devices"`
 }
 
+// ExpectedDevice is a device the setup needs, e.g. the capture card. It
+// is present if a device of its kind matches all identifiers given.
+type ExpectedDevice struct {
+	Name string `yaml:"name"`
+	// Kind is video or audio
+	Kind string `yaml:"kind"`
+
+	// ID is the stable ID or any by-id or by-path link name
+	ID     string `yaml:"id"`
+	Serial string `yaml:"serial"`
+	// USB is vendor and product ID, e.g. "0fd9:0066"
+	USB string `yaml:"usb"`
+}
+
+// Kinds of expected devices.
+const (
+	DeviceKindVideo = "video"
+	DeviceKindAudio = "audio"
+)
+
 type ChecksConfig struct {
 	GPU   bool `yaml:"gpu"`
 	Audio bool `yaml:"audio"`
 	Video bool `yaml:"video"`
 	OBS   bool `yaml:"obs"`
+	Load  bool `yaml:"load"`
+
+	// Display looks for graphical sessions and connected monitors
+	Display bool `yaml:"display"`
+
+	// USB lists USB devices with speed, power and drivers
+	USB bool `yaml:"usb"`
+
+	// AudioBackend forces a backend (pipewire, jack, pulseaudio, alsa)
+	// instead of picking the first active one ("auto").
+	AudioBackend string `yaml:"audio_backend"`
+}
+
+// ProbesConfig limits how long a single probe may take. A probe that
+// runs over keeps its last result, marked as stale.
+type ProbesConfig struct {
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Timeouts overrides Timeout per probe, e.g. {audio: 5s}
+	Timeouts map[string]time.Duration `yaml:"timeouts"`
+}
+
+// Probe names as used in ProbesConfig.Timeouts and /status probes.
+var ProbeNames = []string{"obs", "gpu", "audio", "video", "load", "display", "usb"}
+
+// TimeoutFor returns the timeout of the named probe.
+func (p ProbesConfig) TimeoutFor(name string) time.Duration {
+	if t, ok := p.Timeouts[name]; ok {
+		return t
+	}
+	return p.Timeout
+}
+
+type HotplugConfig struct {
+	Enabled          bool          `yaml:"enabled"`
+	FallbackInterval time.Duration `yaml:"fallback_interval"`
+	LogSize          int           `yaml:"log_size"`
+}
+
+type HistoryConfig struct {
+	Size   int           `yaml:"size"`
+	MaxAge time.Duration `yaml:"max_age"`
+}
+
+type LoadConfig struct {
+	// DiskPaths are checked for free space, e.g. the OBS recording directory.
+	DiskPaths  []string         `yaml:"disk_paths"`
+	Thresholds ThresholdsConfig `yaml:"thresholds"`
+}
+
+// ThresholdsConfig sets when load warnings are raised. 0 disables a check.
+type ThresholdsConfig struct {
+	CPUPercent    float64 `yaml:"cpu_percent"`
+	MemoryPercent float64 `yaml:"memory_percent"`
+	DiskFreeMB    uint64  `yaml:"disk_free_mb"`
 }
 
 type PortalConfig struct {
@@ -47,22 +171,108 @@ type PortalConfig struct {
 	APIKey        string            `yaml:"api_key"`
 	Credentials   CredentialsConfig `yaml:"credentials"`
 	Timeout       time.Duration     `yaml:"timeout"`
+	Interval      time.Duration     `yaml:"interval"`
 	RetryAttempts int               `yaml:"retry_attempts"`
 	RetryDelay    time.Duration     `yaml:"retry_delay"`
 }
 
+// OBSConfig lets the agent start and stop OBS and restart it after a crash.
+type OBSConfig struct {
+	Supervise bool `yaml:"supervise"`
+
+	// Command starts OBS, e.g. [flatpak, run, com.obsproject.Studio]
+	Command []string `yaml:"command"`
+	// Env adds KEY=VALUE pairs, e.g. DISPLAY=:0 when run as a service
+	Env []string `yaml:"env"`
+
+	Profile        string   `yaml:"profile"`
+	Collection     string   `yaml:"collection"`
+	Scene          string   `yaml:"scene"`
+	StartStreaming bool     `yaml:"start_streaming"`
+	StartRecording bool     `yaml:"start_recording"`
+	MinimizeToTray bool     `yaml:"minimize_to_tray"`
+	ExtraArgs      []string `yaml:"extra_args"`
+
+	// StopTimeout is how long OBS gets to exit after SIGTERM before it is killed
+	StopTimeout time.Duration `yaml:"stop_timeout"`
+
+	Restart     RestartConfig `yaml:"restart"`
+	HistorySize int           `yaml:"history_size"`
+}
+
+// RestartConfig restarts OBS after a crash, waiting Backoff, doubled
+// after every crash up to MaxBackoff. Once OBS ran for StableAfter the
+// delay starts over. MaxAttempts 0 means no limit.
+type RestartConfig struct {
+	Enabled     bool          `yaml:"enabled"`
+	Backoff     time.Duration `yaml:"backoff"`
+	MaxBackoff  time.Duration `yaml:"max_backoff"`
+	StableAfter time.Duration `yaml:"stable_after"`
+	MaxAttempts int           `yaml:"max_attempts"`
+}
+
+// Args returns the OBS command line with the configured options.
+func (o OBSConfig) Args() []string {
+	args := append([]string{}, o.Command...)
+	if o.Profile != "" {
+		args = append(args, "--profile", o.Profile)
+	}
+	if o.Collection != "" {
+		args = append(args, "--collection", o.Collection)
+	}
+	if o.Scene != "" {
+		args = append(args, "--scene", o.Scene)
+	}
+	if o.StartStreaming {
+		args = append(args, "--startstreaming")
+	}
+	if o.StartRecording {
+		args = append(args, "--startrecording")
+	}
+	if o.MinimizeToTray {
+		args = append(args, "--minimize-to-tray")
+	}
+	return append(args, o.ExtraArgs...)
+}
+
+// RecordingsConfig lists the directories served under /recordings.
+// Nothing outside of them can be read or deleted.
+type RecordingsConfig struct {
+	Directories []RecordingDirConfig `yaml:"directories"`
+}
+
+type RecordingDirConfig struct {
+	// Name identifies the directory in URLs, e.g. /recordings/obs
+	Name string `yaml:"name"`
+	Path string `yaml:"path"`
+}
+
 type CredentialsConfig struct {
 	Username string `yaml:"username"`
 	Password string `yaml:"password"`
 }
 
+// ReloadConfig controls picking up config changes without a restart.
+// SIGHUP always triggers a reload.
+type ReloadConfig struct {
+	Watch    bool          `yaml:"watch"`
+	Interval time.Duration `yaml:"interval"`
+}
+
+// Resolve returns path, or the first config file found in the default
+// locations if path is empty. It returns "" if there is none.
+func Resolve(path string) string {
+	if path != "" {
+		return path
+	}
+	return findConfigFile()
+}
+
 // Load attempts to load configuration from a file path
 // Falls back to defaults if file doesn't exist
 func Load(path string) (*Config, error) {
 	// If no path specified, search default locations
-	if path == "" {
-		path = findConfigFile()
-	}
+	path = Resolve(path)
 
 	// If still no file found, use defaults
 	if path == "" {
@@ -80,6 +290,11 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("reading config file: %w", err)
 	}
 
+	return Parse(data)
+}
+
+// Parse reads a YAML config on top of the defaults and validates it
+func Parse(data []byte) (*Config, error) {
 	cfg := Default()
 	if err := yaml.Unmarshal(data, cfg); err != nil {
 		return nil, fmt.Errorf("parsing config: %w", err)
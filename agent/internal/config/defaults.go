@@ -13,6 +13,9 @@ func Default() *Config {
 				Write:    5 * time.Second,
 				Shutdown: 5 * time.Second,
 			},
+			TLS: TLSConfig{
+				ClientScopes: []string{ScopeRead},
+			},
 		},
 		Health: HealthConfig{
 			PollingInterval: 2 * time.Second,
@@ -21,6 +24,32 @@ func Default() *Config {
 				Audio: true,
 				Video: true,
 				OBS:   true,
+				Load:  true,
+
+				Display: true,
+				USB:     true,
+
+				AudioBackend: "auto",
+			},
+			Hotplug: HotplugConfig{
+				Enabled:          true,
+				FallbackInterval: 10 * time.Second,
+				LogSize:          100,
+			},
+			History: HistoryConfig{
+				Size:   1800,
+				MaxAge: time.Hour,
+			},
+			Load: LoadConfig{
+				DiskPaths: []string{"/"},
+				Thresholds: ThresholdsConfig{
+					CPUPercent:    90,
+					MemoryPercent: 90,
+					DiskFreeMB:    5 * 1024,
+				},
+			},
+			Probes: ProbesConfig{
+				Timeout: 3 * time.Second,
 			},
 		},
 		Portal: PortalConfig{
@@ -28,8 +57,25 @@ func Default() *Config {
 			URL:           "",
 			APIKey:        "",
 			Timeout:       10 * time.Second,
+			Interval:      30 * time.Second,
 			RetryAttempts: 3,
 			RetryDelay:    5 * time.Second,
 		},
+		OBS: OBSConfig{
+			Supervise:   false,
+			Command:     []string{"obs"},
+			StopTimeout: 10 * time.Second,
+			Restart: RestartConfig{
+				Enabled:     false,
+				Backoff:     2 * time.Second,
+				MaxBackoff:  time.Minute,
+				StableAfter: time.Minute,
+			},
+			HistorySize: 20,
+		},
+		Reload: ReloadConfig{
+			Watch:    true,
+			Interval: 2 * time.Second,
+		},
 	}
 }
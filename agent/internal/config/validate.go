@@ -1,8 +1,12 @@
 package config
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
 )
 
 // Validate checks if the configuration is valid
@@ -15,10 +19,22 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("health config: %w", err)
 	}
 
+	if err := c.OBS.Validate(); err != nil {
+		return fmt.Errorf("obs config: %w", err)
+	}
+
+	if err := c.Recordings.Validate(); err != nil {
+		return fmt.Errorf("recordings config: %w", err)
+	}
+
 	if err := c.Portal.Validate(); err != nil {
 		return fmt.Errorf("portal config: %w", err)
 	}
 
+	if c.Reload.Watch && c.Reload.Interval <= 0 {
+		return errors.New("reload config: interval must be positive when watching")
+	}
+
 	return nil
 }
 
@@ -39,6 +55,80 @@ func (s *ServerConfig) Validate() error {
 		return errors.New("shutdown timeout must be positive")
 	}
 
+	if err := s.TLS.Validate(); err != nil {
+		return fmt.Errorf("tls: %w", err)
+	}
+
+	if err := s.Auth.Validate(); err != nil {
+		return fmt.Errorf("auth: %w", err)
+	}
+
+	// Client certificates are only checked on TLS connections
+	if s.Auth.Enabled && len(s.Auth.Keys) == 0 && (!s.TLS.Enabled || s.TLS.ClientCAFile == "") {
+		return errors.New("auth: enabled without api keys or tls with a client CA")
+	}
+
+	return nil
+}
+
+func (a *AuthConfig) Validate() error {
+	names := map[string]bool{}
+
+	for _, key := range a.Keys {
+		if key.Name == "" {
+			return errors.New("api key without name")
+		}
+		if names[key.Name] {
+			return fmt.Errorf("duplicate api key %q", key.Name)
+		}
+		names[key.Name] = true
+
+		if !validKeyHash(key.Hash) {
+			return fmt.Errorf("api key %q: hash must be \"sha256:<64 hex digits>\"", key.Name)
+		}
+
+		if err := validateScopes(key.Scopes); err != nil {
+			return fmt.Errorf("api key %q: %w", key.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (t *TLSConfig) Validate() error {
+	if !t.Enabled {
+		return nil
+	}
+
+	if t.CertFile == "" || t.KeyFile == "" {
+		return errors.New("cert_file and key_file required when enabled")
+	}
+
+	if t.RequireClientCert && t.ClientCAFile == "" {
+		return errors.New("require_client_cert needs a client_ca_file")
+	}
+
+	return validateScopes(t.ClientScopes)
+}
+
+func validKeyHash(hash string) bool {
+	digest, ok := strings.CutPrefix(hash, "sha256:")
+	if !ok || len(digest) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(digest)
+	return err == nil
+}
+
+func validateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return errors.New("at least one scope required")
+	}
+	for _, scope := range scopes {
+		if scope != ScopeRead && scope != ScopeControl {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+	}
 	return nil
 }
 
@@ -47,9 +137,233 @@ func (h *HealthConfig) Validate() error {
 		return errors.New("polling interval must be positive")
 	}
 
+	if err := h.Checks.Validate(); err != nil {
+		return fmt.Errorf("checks: %w", err)
+	}
+
+	if err := h.Hotplug.Validate(); err != nil {
+		return fmt.Errorf("hotplug: %w", err)
+	}
+
+	if err := h.History.Validate(); err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+
+	if err := h.Load.Validate(); err != nil {
+		return fmt.Errorf("load: %w", err)
+	}
+
+	if err := h.Probes.Validate(); err != nil {
+		return fmt.Errorf("probes: %w", err)
+	}
+
+	names := map[string]bool{}
+	for _, rule := range h.Capabilities {
+		if names[rule.Name] {
+			return fmt.Errorf("capabilities: %q defined twice", rule.Name)
+		}
+		names[rule.Name] = true
+	}
+
+	if err := validateCapabilityRules(h.CapabilityRules()); err != nil {
+		return fmt.Errorf("capabilities: %w", err)
+	}
+
+	expected := map[string]bool{}
+	for i, dev := range h.ExpectedDevices {
+		if err := dev.Validate(); err != nil {
+			return fmt.Errorf("expected_devices[%d]: %w", i, err)
+		}
+		if expected[dev.Name] {
+			return fmt.Errorf("expected_devices: %q defined twice", dev.Name)
+		}
+		expected[dev.Name] = true
+	}
+
+	return nil
+}
+
+func (d *ExpectedDevice) Validate() error {
+	if !validDirName(d.Name) {
+		return fmt.Errorf("name %q may only contain letters, digits, - and _", d.Name)
+	}
+
+	if d.Kind != DeviceKindVideo && d.Kind != DeviceKindAudio {
+		return fmt.Errorf("%s: kind must be %s or %s", d.Name, DeviceKindVideo, DeviceKindAudio)
+	}
+
+	if d.ID == "" && d.Serial == "" && d.USB == "" {
+		return fmt.Errorf("%s: set at least one of id, serial and usb", d.Name)
+	}
+
+	if d.USB != "" {
+		vendor, product, ok := strings.Cut(d.USB, ":")
+		if !ok || len(vendor) != 4 || len(product) != 4 || !isHex(vendor) || !isHex(product) {
+			return fmt.Errorf("%s: usb %q must be vendor:product in hex, e.g. 0fd9:0066", d.Name, d.USB)
+		}
+	}
+
+	return nil
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *ProbesConfig) Validate() error {
+	if p.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+
+	for name, timeout := range p.Timeouts {
+		if !slices.Contains(ProbeNames, name) {
+			return fmt.Errorf("unknown probe %q, use one of %s", name, strings.Join(ProbeNames, ", "))
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("timeout of %s must be positive", name)
+		}
+	}
+
+	return nil
+}
+
+func (l *LoadConfig) Validate() error {
+	for _, path := range l.DiskPaths {
+		if !filepath.IsAbs(path) {
+			return fmt.Errorf("disk path %q must be absolute", path)
+		}
+	}
+
+	if l.Thresholds.CPUPercent < 0 || l.Thresholds.CPUPercent > 100 {
+		return errors.New("cpu threshold must be between 0 and 100")
+	}
+
+	if l.Thresholds.MemoryPercent < 0 || l.Thresholds.MemoryPercent > 100 {
+		return errors.New("memory threshold must be between 0 and 100")
+	}
+
+	return nil
+}
+
+func (h *HistoryConfig) Validate() error {
+	if h.Size < 1 {
+		return errors.New("size must be at least 1")
+	}
+
+	if h.MaxAge < 0 {
+		return errors.New("max age must not be negative")
+	}
+
+	return nil
+}
+
+func (c *ChecksConfig) Validate() error {
+	switch c.AudioBackend {
+	case "", "auto", "pipewire", "pulseaudio", "jack", "alsa":
+		return nil
+	default:
+		return fmt.Errorf("unknown audio backend %q", c.AudioBackend)
+	}
+}
+
+func (h *HotplugConfig) Validate() error {
+	if h.LogSize < 1 {
+		return errors.New("log size must be at least 1")
+	}
+
+	if !h.Enabled {
+		return nil
+	}
+
+	if h.FallbackInterval <= 0 {
+		return errors.New("fallback interval must be positive when hotplug is enabled")
+	}
+
+	return nil
+}
+
+func (o *OBSConfig) Validate() error {
+	if !o.Supervise {
+		return nil
+	}
+
+	if len(o.Command) == 0 || o.Command[0] == "" {
+		return errors.New("command required when supervise is enabled")
+	}
+
+	for _, env := range o.Env {
+		if name, _, ok := strings.Cut(env, "="); !ok || name == "" {
+			return fmt.Errorf("env %q must be KEY=VALUE", env)
+		}
+	}
+
+	if o.StopTimeout <= 0 {
+		return errors.New("stop timeout must be positive")
+	}
+
+	if o.HistorySize <= 0 {
+		return errors.New("history size must be positive")
+	}
+
+	return o.Restart.Validate()
+}
+
+func (r *RestartConfig) Validate() error {
+	if !r.Enabled {
+		return nil
+	}
+
+	if r.Backoff <= 0 || r.MaxBackoff < r.Backoff {
+		return errors.New("restart backoff must be positive and not above max_backoff")
+	}
+
+	if r.StableAfter <= 0 {
+		return errors.New("restart stable_after must be positive")
+	}
+
+	if r.MaxAttempts < 0 {
+		return errors.New("restart max_attempts must not be negative")
+	}
+
 	return nil
 }
 
+func (r *RecordingsConfig) Validate() error {
+	names := map[string]bool{}
+	for _, dir := range r.Directories {
+		if !validDirName(dir.Name) {
+			return fmt.Errorf("directory name %q may only contain letters, digits, - and _", dir.Name)
+		}
+		if names[dir.Name] {
+			return fmt.Errorf("directory %q defined twice", dir.Name)
+		}
+		names[dir.Name] = true
+
+		if !filepath.IsAbs(dir.Path) {
+			return fmt.Errorf("directory %s: path %q must be absolute", dir.Name, dir.Path)
+		}
+	}
+
+	return nil
+}
+
+func validDirName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
 func (p *PortalConfig) Validate() error {
 	if !p.Enabled {
 		return nil // Skip validation if disabled
@@ -67,5 +381,17 @@ func (p *PortalConfig) Validate() error {
 		return errors.New("either api_key or credentials required")
 	}
 
+	if p.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+
+	if p.Interval <= 0 {
+		return errors.New("interval must be positive")
+	}
+
+	if p.RetryAttempts < 0 || p.RetryDelay < 0 {
+		return errors.New("retry settings must not be negative")
+	}
+
 	return nil
 }
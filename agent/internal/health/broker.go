@@ -0,0 +1,121 @@
+package health
+
+import "sync"
+
+const (
+	// brokerBacklog is the number of changes kept for Last-Event-ID resumes.
+	brokerBacklog = 256
+	// subscriberBuffer is how far a subscriber may fall behind before it
+	// is dropped. It can resume from the backlog after reconnecting.
+	subscriberBuffer = 64
+)
+
+// Broker fans out status changes to subscribers and remembers the most
+// recent ones so clients can resume where they left off.
+type Broker struct {
+	mu      sync.Mutex
+	seq     uint64
+	backlog []Change
+	subs    map[chan Change]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		subs: map[chan Change]struct{}{},
+	}
+}
+
+// Subscription is a live feed of changes.
+type Subscription struct {
+	// Seq is the ID of the last change published before subscribing.
+	Seq uint64
+	// Replay holds the missed changes when resuming, oldest first.
+	Replay []Change
+	// Resumed is false if the requested ID is no longer in the backlog
+	// (or none was given); the subscriber then needs a fresh snapshot.
+	Resumed bool
+	C       <-chan Change
+
+	broker *Broker
+	ch     chan Change
+}
+
+// Subscribe registers a new subscriber. If lastID is non-zero and still
+// covered by the backlog, the changes after it are returned for replay.
+func (b *Broker) Subscribe(lastID uint64) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Change, subscriberBuffer)
+	b.subs[ch] = struct{}{}
+
+	sub := &Subscription{
+		Seq:    b.seq,
+		C:      ch,
+		broker: b,
+		ch:     ch,
+	}
+
+	if lastID == 0 || lastID > b.seq {
+		return sub
+	}
+
+	if lastID == b.seq {
+		sub.Resumed = true
+		return sub
+	}
+
+	if len(b.backlog) == 0 || b.backlog[0].ID > lastID+1 {
+		return sub // too old, changes were lost
+	}
+
+	for _, c := range b.backlog {
+		if c.ID > lastID {
+			sub.Replay = append(sub.Replay, c)
+		}
+	}
+	sub.Resumed = true
+	return sub
+}
+
+// Close unregisters the subscription.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	if _, ok := s.broker.subs[s.ch]; ok {
+		delete(s.broker.subs, s.ch)
+		close(s.ch)
+	}
+}
+
+// Publish assigns IDs to the changes and delivers them to all subscribers.
+func (b *Broker) Publish(changes []Change) {
+	if len(changes) == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, c := range changes {
+		b.seq++
+		c.ID = b.seq
+
+		if len(b.backlog) == brokerBacklog {
+			copy(b.backlog, b.backlog[1:])
+			b.backlog = b.backlog[:brokerBacklog-1]
+		}
+		b.backlog = append(b.backlog, c)
+
+		for ch := range b.subs {
+			select {
+			case ch <- c:
+			default:
+				// Too slow, drop it. The client reconnects with Last-Event-ID.
+				delete(b.subs, ch)
+				close(ch)
+			}
+		}
+	}
+}
@@ -3,26 +3,48 @@ package health
 import (
 	"sync"
 	"time"
+
+	"kit.workmate/live-agent/internal/config"
 )
 
 type Cache struct {
 	mu           sync.RWMutex
 	status       *Status
 	capabilities Capabilities
+	rules        []config.CapabilityRule
 	updatedAt    time.Time
+	broker       *Broker
+	history      *History
+}
+
+func NewCache(history *History) *Cache {
+	return &Cache{
+		broker:  NewBroker(),
+		history: history,
+		rules:   config.DefaultCapabilityRules(),
+	}
 }
 
-func NewCache() *Cache {
-	return &Cache{}
+// SetRules replaces the capability rules. They apply from the next Set.
+func (c *Cache) SetRules(rules []config.CapabilityRule) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rules = rules
 }
 
 func (c *Cache) Set(s *Status) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	caps := CollectCapabilities(s, c.rules)
+	c.broker.Publish(Diff(c.status, s, c.capabilities, caps))
+
 	c.status = s
-	c.capabilities = CollectCapabilities(s)
+	c.capabilities = caps
 	c.updatedAt = time.Now()
+
+	c.history.Add(s)
 }
 
 func (c *Cache) Get() *Status {
@@ -45,3 +67,21 @@ func (c *Cache) Capabilities() Capabilities {
 
 	return c.capabilities
 }
+
+// History returns the recorded past snapshots.
+func (c *Cache) History() *History {
+	return c.history
+}
+
+// Subscribe registers for change events and returns the snapshot they
+// apply to. Both are taken together, so no change is missed or doubled.
+func (c *Cache) Subscribe(lastID uint64) (*Subscription, Snapshot) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	sub := c.broker.Subscribe(lastID)
+	return sub, Snapshot{
+		Status:       c.status,
+		Capabilities: c.capabilities,
+	}
+}
@@ -4,4 +4,12 @@ type Capabilities struct {
 	CanVideo  bool `json:"can_video"`
 	CanAudio  bool `json:"can_audio"`
 	CanStream bool `json:"can_stream"`
+
+	// LoadOK is false while any load threshold is exceeded; Warnings says which.
+	LoadOK   bool     `json:"load_ok"`
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Details has every configured capability, including the three above,
+	// with the conditions that are not met.
+	Details map[string]CapabilityResult `json:"details"`
 }
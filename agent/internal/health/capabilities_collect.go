@@ -1,14 +1,21 @@
 package health
 
-func CollectCapabilities(status *Status) Capabilities {
-	canVideo := status.Video.DeviceCount > 0
-	canAudio := status.Audio.Ready
+import "kit.workmate/live-agent/internal/config"
 
-	canStream := canVideo && canAudio && !status.Headless && status.OBS.Running
+func CollectCapabilities(status *Status, rules []config.CapabilityRule) Capabilities {
+	details := EvaluateRules(status, rules)
+
+	var warnings []string
+	if status.Load != nil {
+		warnings = status.Load.Warnings
+	}
 
 	return Capabilities{
-		CanVideo:  canVideo,
-		CanAudio:  canAudio,
-		CanStream: canStream,
+		CanVideo:  details["can_video"].Available,
+		CanAudio:  details["can_audio"].Available,
+		CanStream: details["can_stream"].Available,
+		LoadOK:    len(warnings) == 0,
+		Warnings:  warnings,
+		Details:   details,
 	}
 }
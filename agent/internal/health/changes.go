@@ -0,0 +1,172 @@
+package health
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"kit.workmate/live-agent/internal/system/audio"
+)
+
+// Change event types sent to /events subscribers.
+const (
+	EventSnapshot          = "snapshot"
+	EventDeviceAdded       = "device_added"
+	EventDeviceRemoved     = "device_removed"
+	EventOBSStarted        = "obs_started"
+	EventOBSStopped        = "obs_stopped"
+	EventCapabilityChanged = "capability_changed"
+	EventExpectedDevice    = "expected_device_changed"
+)
+
+// Change is a single typed difference between two status snapshots.
+type Change struct {
+	ID   uint64    `json:"id"`
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	Data any       `json:"data"`
+}
+
+// DeviceChange is the payload of device_added and device_removed. Path
+// is the device node, or the node name for audio sinks and sources. ID
+// is the device's stable identity, if it has one.
+type DeviceChange struct {
+	Kind string `json:"kind"`
+	Path string `json:"path"`
+	ID   string `json:"id,omitempty"`
+}
+
+// CapabilityChange is the payload of capability_changed.
+type CapabilityChange struct {
+	Name  string `json:"name"`
+	Value bool   `json:"value"`
+}
+
+// Snapshot is the payload of the snapshot event.
+type Snapshot struct {
+	Status       *Status      `json:"status"`
+	Capabilities Capabilities `json:"capabilities"`
+}
+
+// Diff returns the changes between two snapshots. A nil old status
+// (first collection) produces no changes, the snapshot covers it.
+func Diff(old, cur *Status, oldCaps, curCaps Capabilities) []Change {
+	if old == nil || cur == nil {
+		return nil
+	}
+
+	var changes []Change
+	add := func(typ string, data any) {
+		changes = append(changes, Change{Type: typ, Time: cur.Timestamp, Data: data})
+	}
+
+	// A removed device is only in the old status, so look IDs up in both
+	ids := map[string]string{}
+	for _, s := range []*Status{old, cur} {
+		for _, dev := range s.Video.Details {
+			ids[dev.Path] = dev.Identity.ID
+		}
+		for _, card := range s.Audio.Cards {
+			ids[cardPath(card)] = card.Identity.ID
+		}
+	}
+
+	diffDevices := func(kind string, before, after []string) {
+		added, removed := diffSets(before, after)
+		for _, path := range added {
+			add(EventDeviceAdded, DeviceChange{Kind: kind, Path: path, ID: ids[path]})
+		}
+		for _, path := range removed {
+			add(EventDeviceRemoved, DeviceChange{Kind: kind, Path: path, ID: ids[path]})
+		}
+	}
+
+	diffDevices("video", old.Video.Devices, cur.Video.Devices)
+	diffDevices("drm", old.GPU.RenderNodes, cur.GPU.RenderNodes)
+	diffDevices("audio_card", cardPaths(old.Audio.Cards), cardPaths(cur.Audio.Cards))
+	diffDevices("audio_sink", nodeNames(old.Audio.Sinks), nodeNames(cur.Audio.Sinks))
+	diffDevices("audio_source", nodeNames(old.Audio.Sources), nodeNames(cur.Audio.Sources))
+
+	if !old.OBS.Running && cur.OBS.Running {
+		add(EventOBSStarted, cur.OBS)
+	}
+	if old.OBS.Running && !cur.OBS.Running {
+		add(EventOBSStopped, cur.OBS)
+	}
+
+	wasPresent := map[string]bool{}
+	for _, dev := range old.ExpectedDevices {
+		wasPresent[dev.Name] = dev.Present
+	}
+	for _, dev := range cur.ExpectedDevices {
+		if was, ok := wasPresent[dev.Name]; ok && was != dev.Present {
+			add(EventExpectedDevice, dev)
+		}
+	}
+
+	names := make([]string, 0, len(curCaps.Details))
+	for name := range curCaps.Details {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if oldCaps.Details[name].Available != curCaps.Details[name].Available {
+			add(EventCapabilityChanged, CapabilityChange{Name: name, Value: curCaps.Details[name].Available})
+		}
+	}
+	if oldCaps.LoadOK != curCaps.LoadOK {
+		add(EventCapabilityChanged, CapabilityChange{Name: "load_ok", Value: curCaps.LoadOK})
+	}
+
+	return changes
+}
+
+// cardPath is the control node of an ALSA card, which hotplug events
+// report as well.
+func cardPath(card audio.Card) string {
+	return fmt.Sprintf("/dev/snd/controlC%d", card.Index)
+}
+
+func cardPaths(cards []audio.Card) []string {
+	paths := make([]string, 0, len(cards))
+	for _, card := range cards {
+		paths = append(paths, cardPath(card))
+	}
+	return paths
+}
+
+// nodeNames identifies sinks and sources by name, their IDs change when
+// the sound server restarts.
+func nodeNames(nodes []audio.Node) []string {
+	names := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	return names
+}
+
+// diffSets returns the entries only present in after (added)
+// and only present in before (removed).
+func diffSets(before, after []string) (added, removed []string) {
+	seen := make(map[string]bool, len(before))
+	for _, s := range before {
+		seen[s] = true
+	}
+
+	for _, s := range after {
+		if seen[s] {
+			delete(seen, s)
+			continue
+		}
+		added = append(added, s)
+	}
+
+	for _, s := range before {
+		if seen[s] {
+			removed = append(removed, s)
+		}
+	}
+
+	return added, removed
+}
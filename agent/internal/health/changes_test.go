@@ -0,0 +1,68 @@
+package health
+
+import (
+	"testing"
+
+	"kit.workmate/live-agent/internal/system/audio"
+	"kit.workmate/live-agent/internal/system/devid"
+)
+
+func TestDiffAudio(t *testing.T) {
+	wave := audio.Card{Index: 1, ID: "Wave3", Identity: devid.Identity{ID: "usb-Elgato_Systems_Elgato_Wave_3_BS22K1A02345-00"}}
+	hdmi := audio.Card{Index: 0, ID: "HDMI"}
+	mic := audio.Node{ID: 55, Name: "alsa_input.usb-Elgato_Wave_3-00.mono-fallback"}
+	speakers := audio.Node{ID: 56, Name: "alsa_output.pci-0000_00_1f.3.analog-stereo"}
+
+	tests := []struct {
+		name     string
+		old, cur AudioStatus
+		want     []Change
+	}{
+		{
+			name: "unchanged after a sound server restart",
+			old:  AudioStatus{Cards: []audio.Card{hdmi}, Sinks: []audio.Node{speakers}},
+			cur:  AudioStatus{Cards: []audio.Card{hdmi}, Sinks: []audio.Node{{ID: 70, Name: speakers.Name}}},
+		},
+		{
+			name: "mic plugged in",
+			old:  AudioStatus{Cards: []audio.Card{hdmi}, Sinks: []audio.Node{speakers}},
+			cur:  AudioStatus{Cards: []audio.Card{hdmi, wave}, Sinks: []audio.Node{speakers}, Sources: []audio.Node{mic}},
+			want: []Change{
+				{Type: EventDeviceAdded, Data: DeviceChange{Kind: "audio_card", Path: "/dev/snd/controlC1", ID: wave.Identity.ID}},
+				{Type: EventDeviceAdded, Data: DeviceChange{Kind: "audio_source", Path: mic.Name}},
+			},
+		},
+		{
+			name: "mic unplugged",
+			old:  AudioStatus{Cards: []audio.Card{hdmi, wave}, Sources: []audio.Node{mic}},
+			cur:  AudioStatus{Cards: []audio.Card{hdmi}},
+			want: []Change{
+				{Type: EventDeviceRemoved, Data: DeviceChange{Kind: "audio_card", Path: "/dev/snd/controlC1", ID: wave.Identity.ID}},
+				{Type: EventDeviceRemoved, Data: DeviceChange{Kind: "audio_source", Path: mic.Name}},
+			},
+		},
+		{
+			name: "default sink moved to another card",
+			old:  AudioStatus{Sinks: []audio.Node{speakers}},
+			cur:  AudioStatus{Sinks: []audio.Node{{ID: 80, Name: "alsa_output.usb-Elgato_Wave_3-00.analog-stereo"}}},
+			want: []Change{
+				{Type: EventDeviceAdded, Data: DeviceChange{Kind: "audio_sink", Path: "alsa_output.usb-Elgato_Wave_3-00.analog-stereo"}},
+				{Type: EventDeviceRemoved, Data: DeviceChange{Kind: "audio_sink", Path: speakers.Name}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := Diff(&Status{Audio: tt.old}, &Status{Audio: tt.cur}, Capabilities{}, Capabilities{})
+			if len(changes) != len(tt.want) {
+				t.Fatalf("Diff() = %+v, want %+v", changes, tt.want)
+			}
+			for i, want := range tt.want {
+				if changes[i].Type != want.Type || changes[i].Data != want.Data {
+					t.Errorf("change %d = %s %+v, want %s %+v", i, changes[i].Type, changes[i].Data, want.Type, want.Data)
+				}
+			}
+		})
+	}
+}
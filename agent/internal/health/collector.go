@@ -2,65 +2,200 @@ package health
 
 import (
 	"os"
+	"reflect"
 	"time"
 
 	"kit.workmate/live-agent/internal/config"
 	"kit.workmate/live-agent/internal/system/audio"
+	"kit.workmate/live-agent/internal/system/display"
 	"kit.workmate/live-agent/internal/system/gpu"
+	"kit.workmate/live-agent/internal/system/load"
 	"kit.workmate/live-agent/internal/system/obs"
+	"kit.workmate/live-agent/internal/system/usb"
 	"kit.workmate/live-agent/internal/system/video"
 )
 
-func Collect(checks config.ChecksConfig) (*Status, error) {
+// Collector runs the configured probes. It keeps state between runs, such
+// as the previous load sample rates are calculated against and the last
+// good result of every probe.
+type Collector struct {
+	checks   config.ChecksConfig
+	expected []config.ExpectedDevice
+	loadCfg  config.LoadConfig
+	load     *load.Sampler
+	runner   *probeRunner
+}
+
+func NewCollector(cfg config.HealthConfig) *Collector {
+	return &Collector{
+		checks:   cfg.Checks,
+		expected: cfg.ExpectedDevices,
+		loadCfg:  cfg.Load,
+		load:     newSampler(cfg.Load),
+		runner:   newProbeRunner(cfg.Probes),
+	}
+}
+
+func newSampler(cfg config.LoadConfig) *load.Sampler {
+	return load.NewSampler("/proc", cfg.DiskPaths, load.Thresholds{
+		CPUPercent:    cfg.Thresholds.CPUPercent,
+		MemoryPercent: cfg.Thresholds.MemoryPercent,
+		DiskFreeBytes: cfg.Thresholds.DiskFreeMB * 1024 * 1024,
+	})
+}
+
+// Reconfigured returns a collector for cfg that takes over c's probe
+// state: a probe still running from before, hung ones included, isn't
+// started a second time, and failing probes keep their last good
+// result. The load sampler stays too unless its settings changed.
+func (c *Collector) Reconfigured(cfg config.HealthConfig) *Collector {
+	next := NewCollector(cfg)
+	c.runner.setTimeouts(cfg.Probes)
+	next.runner = c.runner
+	if reflect.DeepEqual(c.loadCfg, cfg.Load) {
+		next.load = c.load
+	}
+	return next
+}
+
+// Collect runs the enabled probes concurrently. The status is always
+// returned; the error lists the probes that failed or timed out, whose
+// data is their last good result, marked stale in Probes.
+func (c *Collector) Collect() (*Status, error) {
+	values, probes, err := c.runner.runAll(c.probes())
+
 	hostname, _ := os.Hostname()
 
-	// Conditional probing based on config
-	obsStatus := OBSStatus{}
+	status := &Status{
+		Timestamp: time.Now(),
+		Hostname:  hostname,
+		Video: VideoStatus{
+			Devices: []string{},
+			Details: []video.Device{},
+		},
+		Display: display.Status{
+			Sessions: []display.Session{},
+			Sockets:  []display.Socket{},
+			Monitors: []display.Monitor{},
+		},
+		USB: usb.Status{
+			Devices: []usb.Device{},
+		},
+		Probes: probes,
+	}
+
+	// Probes without any good result yet leave the zero value
+	if v, ok := values["obs"].(OBSStatus); ok {
+		status.OBS = v
+	}
+	if v, ok := values["gpu"].(gpu.Status); ok {
+		status.GPU = v
+	}
+	if v, ok := values["audio"].(AudioStatus); ok {
+		status.Audio = v
+	}
+	if v, ok := values["video"].(VideoStatus); ok {
+		status.Video = v
+	}
+	if v, ok := values["load"].(*load.Status); ok {
+		status.Load = v
+	}
+	if v, ok := values["display"].(display.Status); ok {
+		status.Display = v
+	}
+	if v, ok := values["usb"].(usb.Status); ok {
+		status.USB = v
+	}
+
+	status.ExpectedDevices = matchExpected(c.expected, status.Video, status.Audio)
+
+	// The agent usually runs as a service without DISPLAY, so its own
+	// environment only counts if no session was found either
+	status.Headless = os.Getenv("DISPLAY") == "" &&
+		os.Getenv("WAYLAND_DISPLAY") == "" &&
+		!status.Display.Available
+
+	return status, err
+}
+
+// probes returns the probes enabled in the config.
+func (c *Collector) probes() []probe {
+	checks := c.checks
+	var probes []probe
+
 	if checks.OBS {
-		probed := obs.Probe()
-		obsStatus = OBSStatus{
-			Running: probed.Running,
-		}
+		probes = append(probes, probe{"obs", func() (any, error) {
+			probed, err := obs.Probe()
+			if err != nil {
+				return nil, err
+			}
+			return OBSStatus{
+				Running:   probed.Running,
+				Process:   probed.Process,
+				WebSocket: probed.WebSocket,
+			}, nil
+		}})
 	}
 
-	gpuStatus := gpu.Status{}
 	if checks.GPU {
-		gpuStatus = gpu.Probe()
+		probes = append(probes, probe{"gpu", func() (any, error) {
+			return gpu.Probe()
+		}})
 	}
 
-	audioStatus := AudioStatus{}
 	if checks.Audio {
-		probed := audio.Probe()
-		audioStatus = AudioStatus{
-			Backend: probed.Backend,
-			Ready:   probed.Ready,
-		}
+		probes = append(probes, probe{"audio", func() (any, error) {
+			probed, err := audio.Probe(checks.AudioBackend)
+			if err != nil {
+				return nil, err
+			}
+			return AudioStatus{
+				Backend:       probed.Backend,
+				Ready:         probed.Ready,
+				Sinks:         probed.Sinks,
+				Sources:       probed.Sources,
+				DefaultSink:   probed.DefaultSink,
+				DefaultSource: probed.DefaultSource,
+				Cards:         probed.Cards,
+			}, nil
+		}})
 	}
 
-	headless := os.Getenv("DISPLAY") == "" &&
-		os.Getenv("WAYLAND_DISPLAY") == ""
-
-	videoDevices := []string{}
 	if checks.Video {
-		var err error
-		videoDevices, err = video.ScanDevices()
-		if err != nil {
-			videoDevices = []string{}
-		}
+		probes = append(probes, probe{"video", func() (any, error) {
+			devices, err := video.ScanDevices()
+			if err != nil {
+				return nil, err
+			}
+			return VideoStatus{
+				DeviceCount: len(devices),
+				Devices:     devices,
+				Details:     video.Inspect(devices, video.OpenDevice),
+			}, nil
+		}})
 	}
 
-	status := &Status{
-		Timestamp: time.Now(),
-		Hostname:  hostname,
-		Headless:  headless,
-		Video: VideoStatus{
-			DeviceCount: len(videoDevices),
-			Devices:     videoDevices,
-		},
-		OBS:   obsStatus,
-		GPU:   gpuStatus,
-		Audio: audioStatus,
+	if checks.Load {
+		probes = append(probes, probe{"load", func() (any, error) {
+			sampled, err := c.load.Sample()
+			if err != nil {
+				return nil, err
+			}
+			return &sampled, nil
+		}})
+	}
+
+	if checks.Display {
+		probes = append(probes, probe{"display", func() (any, error) {
+			return display.Probe(), nil
+		}})
+	}
+
+	if checks.USB {
+		probes = append(probes, probe{"usb", func() (any, error) {
+			return usb.Probe(), nil
+		}})
 	}
 
-	return status, nil
+	return probes
 }
@@ -0,0 +1,43 @@
+package health
+
+import (
+	"sync"
+
+	"kit.workmate/live-agent/internal/system/hotplug"
+)
+
+// EventLog keeps the most recent hotplug events in memory.
+type EventLog struct {
+	mu     sync.RWMutex
+	events []hotplug.Event
+	size   int
+}
+
+func NewEventLog(size int) *EventLog {
+	return &EventLog{
+		events: make([]hotplug.Event, 0, size),
+		size:   size,
+	}
+}
+
+// Add appends an event and drops the oldest one when the log is full.
+func (l *EventLog) Add(e hotplug.Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.events) == l.size {
+		copy(l.events, l.events[1:])
+		l.events = l.events[:l.size-1]
+	}
+	l.events = append(l.events, e)
+}
+
+// List returns a copy of all events, oldest first.
+func (l *EventLog) List() []hotplug.Event {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	events := make([]hotplug.Event, len(l.events))
+	copy(events, l.events)
+	return events
+}
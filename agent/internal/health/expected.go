@@ -0,0 +1,55 @@
+package health
+
+import (
+	"fmt"
+	"strings"
+
+	"kit.workmate/live-agent/internal/config"
+	"kit.workmate/live-agent/internal/system/devid"
+)
+
+// ExpectedDevice tells whether a device from the config's manifest is
+// plugged in, and which nodes it has right now.
+type ExpectedDevice struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	Present bool   `json:"present"`
+	// Paths are the matching nodes, e.g. /dev/video0 or hw:1 for a sound card
+	Paths []string `json:"paths"`
+}
+
+// matchExpected checks the manifest against the video devices and sound
+// cards in the status.
+func matchExpected(manifest []config.ExpectedDevice, video VideoStatus, audio AudioStatus) []ExpectedDevice {
+	result := make([]ExpectedDevice, 0, len(manifest))
+
+	for _, want := range manifest {
+		found := ExpectedDevice{Name: want.Name, Kind: want.Kind, Paths: []string{}}
+
+		switch want.Kind {
+		case config.DeviceKindVideo:
+			for _, dev := range video.Details {
+				if identityMatches(want, dev.Identity) {
+					found.Paths = append(found.Paths, dev.Path)
+				}
+			}
+		case config.DeviceKindAudio:
+			for _, card := range audio.Cards {
+				if identityMatches(want, card.Identity) {
+					found.Paths = append(found.Paths, fmt.Sprintf("hw:%d", card.Index))
+				}
+			}
+		}
+
+		found.Present = len(found.Paths) > 0
+		result = append(result, found)
+	}
+
+	return result
+}
+
+func identityMatches(want config.ExpectedDevice, id devid.Identity) bool {
+	return (want.ID == "" || id.Matches(want.ID)) &&
+		(want.Serial == "" || want.Serial == id.Serial) &&
+		(want.USB == "" || strings.EqualFold(want.USB, id.USB))
+}
@@ -0,0 +1,119 @@
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"kit.workmate/live-agent/internal/system/gpu"
+	"kit.workmate/live-agent/internal/system/load"
+)
+
+// HistoryEntry is a status that was observed unchanged from From to To.
+// Consecutive identical snapshots are folded into one entry, so a quiet
+// machine only needs a handful of entries for a long time span.
+type HistoryEntry struct {
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Status *Status   `json:"status"`
+}
+
+// History keeps past status snapshots, bounded by entry count and age.
+type History struct {
+	mu      sync.RWMutex
+	entries []HistoryEntry
+	size    int
+	maxAge  time.Duration
+	last    []byte
+}
+
+func NewHistory(size int, maxAge time.Duration) *History {
+	return &History{
+		entries: make([]HistoryEntry, 0, size),
+		size:    size,
+		maxAge:  maxAge,
+	}
+}
+
+// Add records a snapshot, extending the newest entry if nothing but the
+// timestamp changed.
+func (h *History) Add(s *Status) {
+	if s == nil {
+		return
+	}
+
+	fp := fingerprint(s)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if n := len(h.entries); n > 0 && bytes.Equal(fp, h.last) {
+		h.entries[n-1].To = s.Timestamp
+	} else {
+		if len(h.entries) == h.size {
+			copy(h.entries, h.entries[1:])
+			h.entries = h.entries[:h.size-1]
+		}
+		h.entries = append(h.entries, HistoryEntry{From: s.Timestamp, To: s.Timestamp, Status: s})
+		h.last = fp
+	}
+
+	if h.maxAge > 0 {
+		// Drop entries that ended before the window, but always keep the newest
+		cutoff := s.Timestamp.Add(-h.maxAge)
+		drop := 0
+		for drop < len(h.entries)-1 && h.entries[drop].To.Before(cutoff) {
+			drop++
+		}
+		if drop > 0 {
+			h.entries = append(h.entries[:0], h.entries[drop:]...)
+		}
+	}
+}
+
+// Range returns all entries overlapping [since, until], oldest first.
+// A zero since or until leaves that side open.
+func (h *History) Range(since, until time.Time) []HistoryEntry {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	result := []HistoryEntry{}
+	for _, e := range h.entries {
+		if !since.IsZero() && e.To.Before(since) {
+			continue
+		}
+		if !until.IsZero() && e.From.After(until) {
+			continue
+		}
+		result = append(result, e)
+	}
+	return result
+}
+
+// fingerprint serializes a status without its timestamp, probe timings,
+// OBS process counters, GPU telemetry and load readings for comparison.
+func fingerprint(s *Status) []byte {
+	copied := *s
+	copied.Timestamp = time.Time{}
+	copied.Probes = nil
+	if p := copied.OBS.Process; p != nil {
+		stable := *p
+		stable.UptimeSeconds, stable.CPUPercent, stable.RSSBytes, stable.Threads = 0, 0, 0, 0
+		copied.OBS.Process = &stable
+	}
+	// Load readings change on every poll; only crossing a threshold counts
+	if copied.Load != nil {
+		copied.Load = &load.Status{Warnings: copied.Load.Warnings}
+	}
+	// GPU telemetry too, the devices themselves stay
+	if len(copied.GPU.Devices) > 0 {
+		devices := make([]gpu.Device, len(copied.GPU.Devices))
+		for i, d := range copied.GPU.Devices {
+			devices[i] = gpu.Device{RenderNode: d.RenderNode, Card: d.Card, Vendor: d.Vendor, Driver: d.Driver, PCI: d.PCI}
+		}
+		copied.GPU.Devices = devices
+	}
+	data, _ := json.Marshal(copied)
+	return data
+}
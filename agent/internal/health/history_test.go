@@ -0,0 +1,70 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"kit.workmate/live-agent/internal/system/gpu"
+)
+
+func gpuStatus(at time.Time, busy int, driver string) *Status {
+	return &Status{
+		Timestamp: at,
+		GPU: gpu.Status{
+			Present: true,
+			Devices: []gpu.Device{{
+				RenderNode:  "/dev/dri/renderD128",
+				Card:        "card0",
+				Vendor:      "amd",
+				Driver:      driver,
+				BusyPercent: &busy,
+			}},
+		},
+	}
+}
+
+func TestHistoryFoldsGPUTelemetry(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		statuses []*Status
+		entries  int
+	}{
+		{
+			name: "busy changes",
+			statuses: []*Status{
+				gpuStatus(start, 3, "amdgpu"),
+				gpuStatus(start.Add(time.Second), 97, "amdgpu"),
+				gpuStatus(start.Add(2*time.Second), 40, "amdgpu"),
+			},
+			entries: 1,
+		},
+		{
+			name: "driver changes",
+			statuses: []*Status{
+				gpuStatus(start, 3, "amdgpu"),
+				gpuStatus(start.Add(time.Second), 3, "vfio-pci"),
+			},
+			entries: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHistory(10, 0)
+			for _, s := range tt.statuses {
+				h.Add(s)
+			}
+
+			entries := h.Range(time.Time{}, time.Time{})
+			if len(entries) != tt.entries {
+				t.Fatalf("got %d entries, want %d", len(entries), tt.entries)
+			}
+			last := tt.statuses[len(tt.statuses)-1].Timestamp
+			if got := entries[len(entries)-1].To; !got.Equal(last) {
+				t.Errorf("last entry ends at %v, want %v", got, last)
+			}
+		})
+	}
+}
@@ -2,41 +2,98 @@ package health
 
 import (
 	"log"
+	"sync"
 	"time"
 
-	"kit.workmate/live-agent/internal/config"
+	"kit.workmate/live-agent/internal/system/hotplug"
 )
 
+// hotplugSettle gives udev time to finish setting up a new device node
+// (permissions, symlinks) before we probe it.
+const hotplugSettle = 300 * time.Millisecond
+
 type Poller struct {
-	cache    *Cache
-	interval time.Duration
-	checks   config.ChecksConfig
-	stop     chan struct{}
+	cache       *Cache
+	trigger     chan struct{}
+	reconfigure chan struct{}
+	stop        chan struct{}
+	watcher     *hotplug.Watcher
+
+	// mu guards collector and interval, which Reconfigure swaps together,
+	// and lastCollect
+	mu          sync.Mutex
+	collector   *Collector
+	interval    time.Duration
+	lastCollect time.Time
+
+	ready     chan struct{}
+	readyOnce sync.Once
+
+	// lastErr is only touched by the polling goroutine
+	lastErr string
 }
 
-func NewPoller(cache *Cache, interval time.Duration, checks config.ChecksConfig) *Poller {
+func NewPoller(cache *Cache, collector *Collector, interval time.Duration) *Poller {
 	return &Poller{
-		cache:    cache,
-		interval: interval,
-		checks:   checks,
-		stop:     make(chan struct{}),
+		cache:       cache,
+		collector:   collector,
+		interval:    interval,
+		trigger:     make(chan struct{}, 1),
+		reconfigure: make(chan struct{}, 1),
+		stop:        make(chan struct{}),
+		ready:       make(chan struct{}),
 	}
 }
 
+// Ready is closed once the first status is in the cache. Failing probes
+// don't delay it, their errors are part of the status.
+func (p *Poller) Ready() <-chan struct{} {
+	return p.ready
+}
+
+// Healthy reports whether collections keep finishing: the last one ended
+// less than three intervals ago, or 30s for short intervals, which
+// leaves room for probes running into their timeout.
+func (p *Poller) Healthy() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.lastCollect.IsZero() {
+		return false
+	}
+	return time.Since(p.lastCollect) < max(3*p.interval, 30*time.Second)
+}
+
 func (p *Poller) Start() {
 	go func() {
-		ticker := time.NewTicker(p.interval)
+		ticker := time.NewTicker(p.currentInterval())
 		defer ticker.Stop()
 
+		// Don't leave /status empty until the first tick
+		p.collect()
+
 		for {
 			select {
 			case <-ticker.C:
-				status, err := Collect(p.checks)
-				if err != nil {
-					log.Printf("status collect failed: %v", err)
-					continue
+				p.collect()
+
+			case <-p.trigger:
+				select {
+				case <-time.After(hotplugSettle):
+				case <-p.stop:
+					return
+				}
+				// Events that arrived while settling are covered by this run
+				select {
+				case <-p.trigger:
+				default:
 				}
-				p.cache.Set(status)
+				p.collect()
+				ticker.Reset(p.currentInterval())
+
+			case <-p.reconfigure:
+				p.collect()
+				ticker.Reset(p.currentInterval())
 
 			case <-p.stop:
 				return
@@ -45,6 +102,103 @@ func (p *Poller) Start() {
 	}()
 }
 
+// EnableHotplug starts watching device nodes so changes are picked up right
+// away. The ticker then only runs at the slower fallback interval.
+// Must be called before Start.
+func (p *Poller) EnableHotplug(events *EventLog, fallback time.Duration) error {
+	watcher, err := hotplug.Watch(func(e hotplug.Event) {
+		if e.Action != hotplug.ActionChanged {
+			log.Printf("hotplug: %s %s (%s)", e.Kind, e.Action, e.Path)
+			events.Add(e)
+		}
+		p.Trigger()
+	})
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.watcher = watcher
+	p.interval = fallback
+	p.mu.Unlock()
+	return nil
+}
+
+// HotplugEnabled reports whether device nodes are being watched.
+func (p *Poller) HotplugEnabled() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.watcher != nil
+}
+
+// Reconfigure switches to a new collector and interval at once and
+// collects right away, so the cache reflects the new checks.
+func (p *Poller) Reconfigure(collector *Collector, interval time.Duration) {
+	p.mu.Lock()
+	p.collector = collector
+	p.interval = interval
+	p.mu.Unlock()
+
+	select {
+	case p.reconfigure <- struct{}{}:
+	default:
+	}
+}
+
+// Collector returns the collector in use.
+func (p *Poller) Collector() *Collector {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.collector
+}
+
+func (p *Poller) currentInterval() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.interval
+}
+
+// Trigger requests an immediate collection, e.g. after a hotplug event.
+// Multiple triggers before the next run are coalesced.
+func (p *Poller) Trigger() {
+	select {
+	case p.trigger <- struct{}{}:
+	default:
+	}
+}
+
+func (p *Poller) collect() {
+	p.mu.Lock()
+	collector := p.collector
+	p.mu.Unlock()
+
+	status, err := collector.Collect()
+
+	// A failing probe usually keeps failing, don't log it on every poll
+	var msg string
+	if err != nil {
+		msg = err.Error()
+	}
+	if msg != p.lastErr {
+		if msg != "" {
+			log.Printf("status collect: %v", err)
+		} else {
+			log.Println("status collect: all probes recovered")
+		}
+		p.lastErr = msg
+	}
+
+	p.cache.Set(status)
+
+	p.mu.Lock()
+	p.lastCollect = time.Now()
+	p.mu.Unlock()
+	p.readyOnce.Do(func() { close(p.ready) })
+}
+
 func (p *Poller) Stop() {
+	if p.watcher != nil {
+		_ = p.watcher.Close()
+	}
 	close(p.stop)
 }
@@ -0,0 +1,167 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"kit.workmate/live-agent/internal/config"
+)
+
+// errStillRunning is reported while a probe that timed out hasn't returned.
+// It isn't started again until it does, so a hung read can't pile up.
+var errStillRunning = errors.New("previous run still in progress")
+
+// probe returns its part of the status, or an error if it has none.
+type probe struct {
+	name string
+	run  func() (any, error)
+}
+
+// probeState survives between collections, so a probe that fails or
+// times out can fall back to its last good result.
+type probeState struct {
+	running     bool
+	last        any
+	lastSuccess time.Time
+}
+
+type probeRunner struct {
+	mu       sync.Mutex
+	timeouts config.ProbesConfig
+	states   map[string]*probeState
+}
+
+func newProbeRunner(timeouts config.ProbesConfig) *probeRunner {
+	return &probeRunner{timeouts: timeouts, states: map[string]*probeState{}}
+}
+
+// setTimeouts applies reloaded timeouts from the next run on.
+func (r *probeRunner) setTimeouts(timeouts config.ProbesConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timeouts = timeouts
+}
+
+// runAll runs the probes concurrently and waits until each has returned
+// or timed out. Failed probes contribute their last good result.
+func (r *probeRunner) runAll(probes []probe) (map[string]any, map[string]ProbeStatus, error) {
+	type outcome struct {
+		name   string
+		value  any
+		status ProbeStatus
+		err    error
+	}
+
+	results := make(chan outcome, len(probes))
+	for _, p := range probes {
+		go func() {
+			value, status, err := r.run(p)
+			results <- outcome{p.name, value, status, err}
+		}()
+	}
+
+	values := make(map[string]any, len(probes))
+	statuses := make(map[string]ProbeStatus, len(probes))
+	var failed []outcome
+	for range probes {
+		o := <-results
+		values[o.name] = o.value
+		statuses[o.name] = o.status
+		if o.err != nil {
+			failed = append(failed, o)
+		}
+	}
+
+	sort.Slice(failed, func(i, j int) bool { return failed[i].name < failed[j].name })
+	var errs []error
+	for _, o := range failed {
+		errs = append(errs, fmt.Errorf("%s probe: %w", o.name, o.err))
+	}
+
+	return values, statuses, errors.Join(errs...)
+}
+
+func (r *probeRunner) run(p probe) (any, ProbeStatus, error) {
+	r.mu.Lock()
+	state, ok := r.states[p.name]
+	if !ok {
+		state = &probeState{}
+		r.states[p.name] = state
+	}
+	if state.running {
+		r.mu.Unlock()
+		return r.stale(state, 0, errStillRunning)
+	}
+	state.running = true
+	timeout := r.timeouts.TimeoutFor(p.name)
+	r.mu.Unlock()
+
+	type result struct {
+		value any
+		err   error
+	}
+
+	start := time.Now()
+	done := make(chan result, 1)
+	go func() {
+		value, err := p.run()
+
+		r.mu.Lock()
+		state.running = false
+		if err == nil {
+			state.last = value
+			state.lastSuccess = time.Now()
+		}
+		r.mu.Unlock()
+
+		done <- result{value, err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-done:
+		elapsed := time.Since(start)
+		if res.err != nil {
+			return r.stale(state, elapsed, res.err)
+		}
+
+		r.mu.Lock()
+		lastSuccess := state.lastSuccess
+		r.mu.Unlock()
+
+		return res.value, ProbeStatus{
+			DurationMS:  durationMS(elapsed),
+			LastSuccess: &lastSuccess,
+		}, nil
+
+	case <-timer.C:
+		return r.stale(state, time.Since(start), fmt.Errorf("timed out after %s", timeout))
+	}
+}
+
+// stale returns the last good result of a probe that didn't deliver a new one.
+func (r *probeRunner) stale(state *probeState, elapsed time.Duration, err error) (any, ProbeStatus, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := ProbeStatus{
+		DurationMS: durationMS(elapsed),
+		Error:      err.Error(),
+		Stale:      true,
+	}
+	if !state.lastSuccess.IsZero() {
+		lastSuccess := state.lastSuccess
+		status.LastSuccess = &lastSuccess
+	}
+
+	return state.last, status, err
+}
+
+func durationMS(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,54 @@
+package health
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kit.workmate/live-agent/internal/config"
+)
+
+func TestReconfiguredKeepsProbeState(t *testing.T) {
+	cfg := config.HealthConfig{Probes: config.ProbesConfig{Timeout: 10 * time.Millisecond}}
+	c := NewCollector(cfg)
+
+	release := make(chan struct{})
+	defer close(release)
+	var calls atomic.Int32
+	hung := probe{"audio", func() (any, error) {
+		if calls.Add(1) == 1 {
+			return AudioStatus{Backend: "alsa"}, nil
+		}
+		<-release
+		return AudioStatus{}, nil
+	}}
+
+	if _, _, err := c.runner.runAll([]probe{hung}); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if _, _, err := c.runner.runAll([]probe{hung}); err == nil {
+		t.Fatal("second run didn't time out")
+	}
+
+	// The reload must not start the hung probe again, and the last good
+	// result stays available
+	cfg.Probes.Timeout = time.Second
+	next := c.Reconfigured(cfg)
+	values, statuses, err := next.runner.runAll([]probe{hung})
+	if !errors.Is(err, errStillRunning) {
+		t.Errorf("err = %v, want %v", err, errStillRunning)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("probe started %d times, want 2", n)
+	}
+	if v, ok := values["audio"].(AudioStatus); !ok || v.Backend != "alsa" {
+		t.Errorf("value = %#v, want the last good result", values["audio"])
+	}
+	if s := statuses["audio"]; !s.Stale || s.LastSuccess == nil {
+		t.Errorf("status = %+v, want stale with a last success", s)
+	}
+	if got := next.runner.timeouts.TimeoutFor("audio"); got != time.Second {
+		t.Errorf("timeout = %s, want the reloaded 1s", got)
+	}
+}
@@ -0,0 +1,244 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"kit.workmate/live-agent/internal/config"
+)
+
+// CapabilityResult tells whether a capability is available and, if not,
+// which of its conditions failed.
+type CapabilityResult struct {
+	Available bool             `json:"available"`
+	Unmet     []UnmetCondition `json:"unmet,omitempty"`
+}
+
+// UnmetCondition is a failed condition with a human-readable reason.
+type UnmetCondition struct {
+	Condition string `json:"condition"`
+	Reason    string `json:"reason"`
+	Actual    any    `json:"actual,omitempty"`
+}
+
+// EvaluateRules checks the rules in order, so a rule can refer to the
+// capabilities defined before it.
+func EvaluateRules(status *Status, rules []config.CapabilityRule) map[string]CapabilityResult {
+	results := make(map[string]CapabilityResult, len(rules))
+
+	// Rules work on the same field names the API shows
+	var doc any
+	if data, err := json.Marshal(status); err == nil {
+		_ = json.Unmarshal(data, &doc)
+	}
+
+	for _, rule := range rules {
+		result := CapabilityResult{Available: true}
+
+		for _, c := range rule.Conditions {
+			if unmet, ok := check(doc, c, results); !ok {
+				result.Available = false
+				result.Unmet = append(result.Unmet, unmet)
+			}
+		}
+
+		results[rule.Name] = result
+	}
+
+	return results
+}
+
+func check(doc any, c config.Condition, results map[string]CapabilityResult) (UnmetCondition, bool) {
+	if c.Capability != "" {
+		if results[c.Capability].Available {
+			return UnmetCondition{}, true
+		}
+		return UnmetCondition{
+			Condition: c.Capability,
+			Reason:    reason(c, c.Capability+" is not available"),
+		}, false
+	}
+
+	values := resolve(doc, c.Field)
+	for _, v := range values {
+		if compare(v, c.Op, c.Value) {
+			return UnmetCondition{}, true
+		}
+	}
+
+	unmet := UnmetCondition{Condition: describe(c)}
+	switch len(values) {
+	case 0:
+		unmet.Reason = reason(c, c.Field+" is not reported")
+	case 1:
+		unmet.Actual = values[0]
+		unmet.Reason = reason(c, fmt.Sprintf("%s is %v", c.Field, values[0]))
+	default:
+		unmet.Actual = values
+		unmet.Reason = reason(c, fmt.Sprintf("no %s matches", c.Field))
+	}
+	return unmet, false
+}
+
+func reason(c config.Condition, fallback string) string {
+	if c.Reason != "" {
+		return c.Reason
+	}
+	return fallback
+}
+
+func describe(c config.Condition) string {
+	if c.Op == "exists" {
+		return c.Field + " exists"
+	}
+	return fmt.Sprintf("%s %s %v", c.Field, c.Op, c.Value)
+}
+
+// resolve follows a dotted path and returns every value it reaches.
+// Arrays fan out, a [key=value] segment keeps matching elements only.
+func resolve(doc any, path string) []any {
+	current := []any{doc}
+	segments := splitPath(path)
+
+	for i, seg := range segments {
+		name, filter, hasFilter := strings.Cut(seg, "[")
+		filter = strings.TrimSuffix(filter, "]")
+
+		var next []any
+		for _, v := range current {
+			if name != "" {
+				m, ok := v.(map[string]any)
+				if !ok {
+					continue
+				}
+				v, ok = m[name]
+				if !ok || v == nil {
+					continue
+				}
+			}
+
+			list, isList := v.([]any)
+			switch {
+			case hasFilter && isList:
+				key, want, _ := strings.Cut(filter, "=")
+				for _, item := range list {
+					if m, ok := item.(map[string]any); ok && fmt.Sprint(m[key]) == want {
+						next = append(next, item)
+					}
+				}
+			case isList && i < len(segments)-1:
+				// A list as final value stays whole for contains/exists
+				next = append(next, list...)
+			default:
+				next = append(next, v)
+			}
+		}
+		current = next
+	}
+
+	return current
+}
+
+// splitPath splits on dots outside of brackets, since filter values
+// like paths may contain dots.
+func splitPath(path string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range path {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				parts = append(parts, path[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, path[start:])
+}
+
+func compare(actual any, op string, want any) bool {
+	switch op {
+	case "exists":
+		return !empty(actual)
+	case "contains":
+		switch a := actual.(type) {
+		case []any:
+			for _, item := range a {
+				if equal(item, want) {
+					return true
+				}
+			}
+			return false
+		case string:
+			return strings.Contains(a, fmt.Sprint(want))
+		}
+		return false
+	case "==":
+		return equal(actual, want)
+	case "!=":
+		return !equal(actual, want)
+	}
+
+	a, ok1 := number(actual)
+	b, ok2 := number(want)
+	if !ok1 || !ok2 {
+		return false
+	}
+	switch op {
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	}
+	return false
+}
+
+func equal(a, b any) bool {
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			return x == y
+		}
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func empty(v any) bool {
+	switch x := v.(type) {
+	case nil:
+		return true
+	case string:
+		return x == ""
+	case []any:
+		return len(x) == 0
+	case map[string]any:
+		return len(x) == 0
+	}
+	return false
+}
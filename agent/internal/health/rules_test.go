@@ -0,0 +1,160 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"kit.workmate/live-agent/internal/config"
+)
+
+const rulesDoc = `{
+	"headless": false,
+	"obs": {"running": true, "process": null},
+	"video": {
+		"device_count": 2,
+		"details": [
+			{"path": "/dev/video0", "card": "Cam Link 4K", "capture": true, "identity": {"id": "usb-Elgato_Cam_Link_4K_0004-video-index0"}},
+			{"path": "/dev/video2", "card": "Integrated Camera", "capture": false, "formats": []}
+		]
+	},
+	"audio": {"ready": true, "sources": [{"name": "alsa_input.usb-Elgato_Wave_3-00.mono-fallback", "channels": 1}]},
+	"load": {"cpu": {"usage_percent": 42.5}},
+	"gpu": {"vendors": ["amd", "intel"]}
+}`
+
+func parseDoc(t *testing.T) any {
+	t.Helper()
+	var doc any
+	if err := json.Unmarshal([]byte(rulesDoc), &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestResolve(t *testing.T) {
+	doc := parseDoc(t)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"headless", "[false]"},
+		{"video.device_count", "[2]"},
+		{"video.details.card", "[Cam Link 4K Integrated Camera]"},
+		{"video.details[capture=true].card", "[Cam Link 4K]"},
+		{"video.details[path=/dev/video2].card", "[Integrated Camera]"},
+		{"video.details[capture=true].identity.id", "[usb-Elgato_Cam_Link_4K_0004-video-index0]"},
+		{"gpu.vendors", "[[amd intel]]"},
+		{"video.details.formats", "[[]]"},
+		{"obs.process", "[]"},
+		{"obs.process.pid", "[]"},
+		{"audio.missing", "[]"},
+		{"headless.value", "[]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got := fmt.Sprint(resolve(doc, tt.path))
+			if got != tt.want {
+				t.Errorf("resolve(%q) = %s, want %s", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitPath(t *testing.T) {
+	got := fmt.Sprint(splitPath("video.details[path=/dev/v4l/by-id/usb-Cam.Link-video-index0].card"))
+	want := "[video details[path=/dev/v4l/by-id/usb-Cam.Link-video-index0] card]"
+	if got != want {
+		t.Errorf("splitPath() = %s, want %s", got, want)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		actual any
+		op     string
+		want   any
+		result bool
+	}{
+		{float64(2), ">=", 1, true},
+		{float64(1), ">=", 1, true},
+		{float64(0), ">=", 1, false},
+		{42.5, "<", 80, true},
+		{42.5, ">", "40", true},
+		{float64(3), "==", 3, true},
+		{float64(3), "!=", 3, false},
+		{true, "==", true, true},
+		{false, "==", true, false},
+		{"pipewire", "==", "pipewire", true},
+		{"pipewire", "!=", "alsa", true},
+		{"Cam Link 4K", ">", 1, false},
+		{[]any{"amd", "intel"}, "contains", "intel", true},
+		{[]any{"amd"}, "contains", "nvidia", false},
+		{[]any{float64(48000)}, "contains", 48000, true},
+		{"alsa_input.usb-Elgato_Wave_3-00", "contains", "Wave_3", true},
+		{float64(1), "contains", 1, false},
+		{"x", "exists", nil, true},
+		{"", "exists", nil, false},
+		{[]any{}, "exists", nil, false},
+		{map[string]any{}, "exists", nil, false},
+		{false, "exists", nil, true},
+		{float64(1), "~", 1, false},
+	}
+
+	for _, tt := range tests {
+		name := fmt.Sprintf("%v %s %v", tt.actual, tt.op, tt.want)
+		t.Run(name, func(t *testing.T) {
+			if got := compare(tt.actual, tt.op, tt.want); got != tt.result {
+				t.Errorf("compare(%v, %q, %v) = %v, want %v", tt.actual, tt.op, tt.want, got, tt.result)
+			}
+		})
+	}
+}
+
+func TestEvaluateRules(t *testing.T) {
+	status := &Status{
+		Video: VideoStatus{DeviceCount: 1},
+		Audio: AudioStatus{Ready: false},
+	}
+
+	rules := []config.CapabilityRule{
+		{Name: "can_video", Conditions: []config.Condition{
+			{Field: "video.device_count", Op: ">=", Value: 1},
+		}},
+		{Name: "can_audio", Conditions: []config.Condition{
+			{Field: "audio.ready", Op: "==", Value: true, Reason: "audio backend not ready"},
+		}},
+		{Name: "can_stream", Conditions: []config.Condition{
+			{Capability: "can_video"},
+			{Capability: "can_audio", Reason: "no audio input"},
+			{Field: "gpu.vendors", Op: "contains", Value: "nvidia"},
+		}},
+	}
+
+	results := EvaluateRules(status, rules)
+
+	tests := []struct {
+		name      string
+		available bool
+		reasons   string
+	}{
+		{"can_video", true, "[]"},
+		{"can_audio", false, "[audio backend not ready]"},
+		{"can_stream", false, "[no audio input gpu.vendors is not reported]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := results[tt.name]
+			reasons := make([]string, 0, len(result.Unmet))
+			for _, unmet := range result.Unmet {
+				reasons = append(reasons, unmet.Reason)
+			}
+			if result.Available != tt.available || fmt.Sprint(reasons) != tt.reasons {
+				t.Errorf("%s = %v %v, want %v %s", tt.name, result.Available, reasons, tt.available, tt.reasons)
+			}
+		})
+	}
+}
@@ -3,30 +3,60 @@ package health
 import (
 	"time"
 
+	"kit.workmate/live-agent/internal/system/audio"
+	"kit.workmate/live-agent/internal/system/display"
 	"kit.workmate/live-agent/internal/system/gpu"
+	"kit.workmate/live-agent/internal/system/load"
+	"kit.workmate/live-agent/internal/system/obs"
+	"kit.workmate/live-agent/internal/system/usb"
+	"kit.workmate/live-agent/internal/system/video"
 )
 
 //Status ist das zentrale Objekt,
 //das der Agent nach außen liefert.
 
 type Status struct {
-	Timestamp time.Time   `json:"timestamp"`
-	Hostname  string      `json:"hostname"`
-	Headless  bool        `json:"headless"`
-	Video     VideoStatus `json:"video"`
-	Audio     AudioStatus `json:"audio"`
-	OBS       OBSStatus   `json:"obs"`
-	GPU       gpu.Status  `json:"gpu"`
+	Timestamp time.Time      `json:"timestamp"`
+	Hostname  string         `json:"hostname"`
+	Headless  bool           `json:"headless"`
+	Video     VideoStatus    `json:"video"`
+	Audio     AudioStatus    `json:"audio"`
+	OBS       OBSStatus      `json:"obs"`
+	GPU       gpu.Status     `json:"gpu"`
+	Load      *load.Status   `json:"load,omitempty"`
+	Display   display.Status `json:"display"`
+	USB       usb.Status     `json:"usb"`
+
+	ExpectedDevices []ExpectedDevice `json:"expected_devices"`
+
+	Probes map[string]ProbeStatus `json:"probes"`
 }
 type VideoStatus struct {
-	DeviceCount int      `json:"device_count"`
-	Devices     []string `json:"devices"`
+	DeviceCount int            `json:"device_count"`
+	Devices     []string       `json:"devices"`
+	Details     []video.Device `json:"details"`
 }
 
 type AudioStatus struct {
-	Backend string `json:"backend"`
-	Ready   bool   `json:"ready"`
+	Backend       string       `json:"backend"`
+	Ready         bool         `json:"ready"`
+	Sinks         []audio.Node `json:"sinks"`
+	Sources       []audio.Node `json:"sources"`
+	DefaultSink   string       `json:"default_sink,omitempty"`
+	DefaultSource string       `json:"default_source,omitempty"`
+	Cards         []audio.Card `json:"cards,omitempty"`
 }
 type OBSStatus struct {
-	Running bool `json:"running"`
+	Running   bool                 `json:"running"`
+	Process   *obs.Process         `json:"process,omitempty"`
+	WebSocket *obs.WebSocketConfig `json:"websocket,omitempty"`
+}
+
+// ProbeStatus describes the last run of a single probe. Stale means the
+// run failed or timed out and the probe's data is from LastSuccess.
+type ProbeStatus struct {
+	DurationMS  float64    `json:"duration_ms"`
+	Error       string     `json:"error,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	Stale       bool       `json:"stale,omitempty"`
 }
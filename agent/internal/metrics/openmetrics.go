@@ -0,0 +1,370 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"kit.workmate/live-agent/internal/buildinfo"
+	"kit.workmate/live-agent/internal/health"
+	"kit.workmate/live-agent/internal/system/gpu"
+	"kit.workmate/live-agent/internal/system/load"
+	"kit.workmate/live-agent/internal/system/specs"
+)
+
+// ContentType is the OpenMetrics text exposition format.
+const ContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+const prefix = "workmate_agent_"
+
+// Snapshot is everything that goes into one scrape.
+type Snapshot struct {
+	Status       *health.Status
+	Capabilities health.Capabilities
+	Specs        specs.Specs
+	UpdatedAt    time.Time
+	Now          time.Time
+}
+
+// Write renders the snapshot in OpenMetrics text format.
+func Write(out io.Writer, s Snapshot) error {
+	w := &writer{out: out}
+
+	w.family("build", "info", "Agent build information.")
+	w.sample("build_info", labels{
+		"name", buildinfo.Name,
+		"version", buildinfo.Version,
+		"commit", buildinfo.Commit,
+		"build_time", buildinfo.BuildTime,
+	}, 1)
+
+	w.family("cpu_cores", "gauge", "Number of logical CPU cores.")
+	w.sample("cpu_cores", nil, float64(s.Specs.CPU.Cores))
+
+	w.family("memory_total_bytes", "gauge", "Total system memory.")
+	w.sample("memory_total_bytes", nil, float64(s.Specs.Memory.TotalMB)*1024*1024)
+
+	w.family("status_ready", "gauge", "Whether a status has been collected yet.")
+	if s.Status == nil {
+		w.sample("status_ready", nil, 0)
+		w.eof()
+		return w.err
+	}
+	w.sample("status_ready", nil, 1)
+
+	if !s.UpdatedAt.IsZero() {
+		w.family("cache_age_seconds", "gauge", "Time since the status cache was last updated.")
+		w.sample("cache_age_seconds", nil, s.Now.Sub(s.UpdatedAt).Seconds())
+	}
+
+	status := s.Status
+
+	w.family("headless", "gauge", "Whether no display server is available.")
+	w.sample("headless", nil, boolValue(status.Headless))
+
+	w.family("display_available", "gauge", "Whether a graphical session or display socket exists.")
+	w.sample("display_available", nil, boolValue(status.Display.Available))
+
+	w.family("monitor_connected", "gauge", "Connected monitors, 1 if enabled, 0 if disabled.")
+	for _, m := range status.Display.Monitors {
+		w.sample("monitor_connected", labels{"card", m.Card, "connector", m.Connector, "name", m.Name}, boolValue(m.Enabled))
+	}
+
+	w.family("usb_devices", "gauge", "Number of USB devices, root hubs excluded.")
+	w.sample("usb_devices", nil, float64(len(status.USB.Devices)))
+
+	w.family("usb_device_speed_bits_per_second", "gauge", "Negotiated speed of each USB device.")
+	for _, d := range status.USB.Devices {
+		w.sample("usb_device_speed_bits_per_second", labels{"path", d.Path, "vendor_id", d.VendorID, "product_id", d.ProductID}, d.SpeedMbps*1e6)
+	}
+
+	w.family("usb_device_port_speed_bits_per_second", "gauge", "Fastest speed the port of each USB device supports.")
+	for _, d := range status.USB.Devices {
+		w.sample("usb_device_port_speed_bits_per_second", labels{"path", d.Path, "vendor_id", d.VendorID, "product_id", d.ProductID}, d.PortSpeedMbps*1e6)
+	}
+
+	w.family("usb_device_degraded", "gauge", "Whether a USB device runs below the speed it supports.")
+	for _, d := range status.USB.Devices {
+		w.sample("usb_device_degraded", labels{"path", d.Path, "vendor_id", d.VendorID, "product_id", d.ProductID}, boolValue(d.Degraded))
+	}
+
+	w.family("video_devices", "gauge", "Number of video devices.")
+	w.sample("video_devices", nil, float64(status.Video.DeviceCount))
+
+	if len(status.ExpectedDevices) > 0 {
+		w.family("expected_device_present", "gauge", "Whether a device from the expected-device manifest is plugged in.")
+		for _, d := range status.ExpectedDevices {
+			w.sample("expected_device_present", labels{"name", d.Name, "kind", d.Kind}, boolValue(d.Present))
+		}
+	}
+
+	w.family("audio_ready", "gauge", "Whether the audio backend is ready.")
+	w.sample("audio_ready", labels{"backend", status.Audio.Backend}, boolValue(status.Audio.Ready))
+
+	w.family("audio_nodes", "gauge", "Number of audio sinks and sources.")
+	w.sample("audio_nodes", labels{"direction", "sink"}, float64(len(status.Audio.Sinks)))
+	w.sample("audio_nodes", labels{"direction", "source"}, float64(len(status.Audio.Sources)))
+
+	w.family("obs_running", "gauge", "Whether OBS Studio is running.")
+	w.sample("obs_running", nil, boolValue(status.OBS.Running))
+
+	if p := status.OBS.Process; p != nil {
+		w.family("obs_cpu_percent", "gauge", "OBS CPU usage, 100 is one full core.")
+		w.sample("obs_cpu_percent", labels{"install_type", p.InstallType}, p.CPUPercent)
+
+		w.family("obs_resident_memory_bytes", "gauge", "OBS resident memory.")
+		w.sample("obs_resident_memory_bytes", nil, float64(p.RSSBytes))
+
+		w.family("obs_threads", "gauge", "OBS thread count.")
+		w.sample("obs_threads", nil, float64(p.Threads))
+
+		w.family("obs_start_time_seconds", "gauge", "OBS process start time since the epoch.")
+		w.sample("obs_start_time_seconds", nil, float64(p.StartedAt.Unix()))
+	}
+
+	w.family("gpu_present", "gauge", "Whether a GPU render node is present.")
+	w.sample("gpu_present", nil, boolValue(status.GPU.Present))
+
+	if len(status.GPU.Vendors) > 0 {
+		w.family("gpu_vendor_present", "gauge", "GPUs present per vendor.")
+		for _, vendor := range status.GPU.Vendors {
+			w.sample("gpu_vendor_present", labels{"vendor", vendor}, 1)
+		}
+	}
+
+	writeGPUDevices(w, status.GPU.Devices)
+
+	if status.Load != nil {
+		writeLoad(w, status.Load)
+	}
+
+	w.family("capability", "gauge", "Capability flags.")
+	names := make([]string, 0, len(s.Capabilities.Details))
+	for name := range s.Capabilities.Details {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		w.sample("capability", labels{"name", name}, boolValue(s.Capabilities.Details[name].Available))
+	}
+	w.sample("capability", labels{"name", "load_ok"}, boolValue(s.Capabilities.LoadOK))
+
+	if len(status.Probes) > 0 {
+		w.family("probe_duration_seconds", "gauge", "Duration of the last run of each probe.")
+		for _, name := range sortedKeys(status.Probes) {
+			seconds := math.Round(status.Probes[name].DurationMS*1000) / 1e6
+			w.sample("probe_duration_seconds", labels{"probe", name}, seconds)
+		}
+
+		w.family("probe_stale", "gauge", "Whether the probe's last run failed or timed out and its data is from an earlier run.")
+		for _, name := range sortedKeys(status.Probes) {
+			w.sample("probe_stale", labels{"probe", name}, boolValue(status.Probes[name].Stale))
+		}
+
+		w.family("probe_last_success_seconds", "gauge", "Time of the last successful run of each probe.")
+		for _, name := range sortedKeys(status.Probes) {
+			if last := status.Probes[name].LastSuccess; last != nil {
+				w.sample("probe_last_success_seconds", labels{"probe", name}, float64(last.Unix()))
+			}
+		}
+	}
+
+	w.eof()
+	return w.err
+}
+
+func writeLoad(w *writer, l *load.Status) {
+	w.family("cpu_usage_percent", "gauge", "CPU usage since the previous sample, per core and overall.")
+	w.sample("cpu_usage_percent", labels{"core", "all"}, l.CPU.UsagePercent)
+	for i, usage := range l.CPU.Cores {
+		w.sample("cpu_usage_percent", labels{"core", strconv.Itoa(i)}, usage)
+	}
+
+	w.family("load_average", "gauge", "System load average.")
+	w.sample("load_average", labels{"period", "1m"}, l.CPU.Load1)
+	w.sample("load_average", labels{"period", "5m"}, l.CPU.Load5)
+	w.sample("load_average", labels{"period", "15m"}, l.CPU.Load15)
+
+	w.family("memory_available_bytes", "gauge", "Memory available without swapping.")
+	w.sample("memory_available_bytes", nil, float64(l.Memory.AvailableBytes))
+
+	w.family("swap_used_bytes", "gauge", "Used swap space.")
+	w.sample("swap_used_bytes", nil, float64(l.Memory.SwapUsedBytes))
+
+	if len(l.Disks) > 0 {
+		w.family("disk_free_bytes", "gauge", "Free space available to unprivileged users.")
+		for _, d := range l.Disks {
+			if d.Error == "" {
+				w.sample("disk_free_bytes", labels{"path", d.Path}, float64(d.FreeBytes))
+			}
+		}
+
+		w.family("disk_total_bytes", "gauge", "Size of the filesystem.")
+		for _, d := range l.Disks {
+			if d.Error == "" {
+				w.sample("disk_total_bytes", labels{"path", d.Path}, float64(d.TotalBytes))
+			}
+		}
+	}
+
+	if len(l.Network) > 0 {
+		w.family("network_receive_bytes", "counter", "Bytes received per interface.")
+		for _, iface := range l.Network {
+			w.sample("network_receive_bytes_total", labels{"interface", iface.Name}, float64(iface.RxBytes))
+		}
+
+		w.family("network_transmit_bytes", "counter", "Bytes sent per interface.")
+		for _, iface := range l.Network {
+			w.sample("network_transmit_bytes_total", labels{"interface", iface.Name}, float64(iface.TxBytes))
+		}
+	}
+
+	w.family("load_warnings", "gauge", "Number of load thresholds currently exceeded.")
+	w.sample("load_warnings", nil, float64(len(l.Warnings)))
+}
+
+// writeGPUDevices emits one family per telemetry value, skipping values
+// no device reports.
+func writeGPUDevices(w *writer, devices []gpu.Device) {
+	gauges := []struct {
+		name, help string
+		value      func(gpu.Device) (float64, bool)
+	}{
+		{"gpu_busy_percent", "GPU utilisation.", func(d gpu.Device) (float64, bool) {
+			return intValue(d.BusyPercent)
+		}},
+		{"gpu_vram_used_bytes", "Used video memory.", func(d gpu.Device) (float64, bool) {
+			return uintValue(d.VRAMUsedBytes)
+		}},
+		{"gpu_vram_total_bytes", "Total video memory.", func(d gpu.Device) (float64, bool) {
+			return uintValue(d.VRAMTotalBytes)
+		}},
+		{"gpu_frequency_hertz", "Current GPU clock.", func(d gpu.Device) (float64, bool) {
+			v, ok := intValue(d.FreqMHz)
+			return v * 1e6, ok
+		}},
+		{"gpu_max_frequency_hertz", "Maximum GPU clock.", func(d gpu.Device) (float64, bool) {
+			v, ok := intValue(d.MaxFreqMHz)
+			return v * 1e6, ok
+		}},
+		{"gpu_temperature_celsius", "GPU temperature.", func(d gpu.Device) (float64, bool) {
+			return floatValue(d.TemperatureC)
+		}},
+		{"gpu_power_watts", "GPU power draw.", func(d gpu.Device) (float64, bool) {
+			return floatValue(d.PowerW)
+		}},
+	}
+
+	for _, g := range gauges {
+		headerWritten := false
+		for _, d := range devices {
+			v, ok := g.value(d)
+			if !ok {
+				continue
+			}
+			if !headerWritten {
+				w.family(g.name, "gauge", g.help)
+				headerWritten = true
+			}
+			w.sample(g.name, labels{"render_node", d.RenderNode, "vendor", d.Vendor}, v)
+		}
+	}
+}
+
+func intValue(v *int) (float64, bool) {
+	if v == nil {
+		return 0, false
+	}
+	return float64(*v), true
+}
+
+func uintValue(v *uint64) (float64, bool) {
+	if v == nil {
+		return 0, false
+	}
+	return float64(*v), true
+}
+
+func floatValue(v *float64) (float64, bool) {
+	if v == nil {
+		return 0, false
+	}
+	return *v, true
+}
+
+// labels are alternating name/value pairs.
+type labels []string
+
+type writer struct {
+	out io.Writer
+	err error
+}
+
+func (w *writer) printf(format string, args ...any) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = fmt.Fprintf(w.out, format, args...)
+}
+
+func (w *writer) family(name, typ, help string) {
+	w.printf("# TYPE %s%s %s\n", prefix, name, typ)
+	for _, unit := range []string{"seconds", "bytes", "hertz", "celsius", "watts"} {
+		if strings.HasSuffix(name, "_"+unit) {
+			w.printf("# UNIT %s%s %s\n", prefix, name, unit)
+		}
+	}
+	w.printf("# HELP %s%s %s\n", prefix, name, help)
+}
+
+func (w *writer) sample(name string, l labels, value float64) {
+	var b strings.Builder
+	b.WriteString(prefix)
+	b.WriteString(name)
+
+	if len(l) > 0 {
+		b.WriteByte('{')
+		for i := 0; i+1 < len(l); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(l[i])
+			b.WriteString(`="`)
+			b.WriteString(escape(l[i+1]))
+			b.WriteByte('"')
+		}
+		b.WriteByte('}')
+	}
+
+	w.printf("%s %s\n", b.String(), strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+func (w *writer) eof() {
+	w.printf("# EOF\n")
+}
+
+func escape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
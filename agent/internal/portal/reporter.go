@@ -0,0 +1,282 @@
+package portal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"kit.workmate/live-agent/internal/api"
+	"kit.workmate/live-agent/internal/config"
+	"kit.workmate/live-agent/internal/health"
+)
+
+// Registration is sent once on startup and again whenever the portal
+// no longer knows the agent (e.g. after a portal restart).
+type Registration struct {
+	AgentID      string              `json:"agent_id"`
+	Hostname     string              `json:"hostname"`
+	Info         api.Info            `json:"info"`
+	Capabilities health.Capabilities `json:"capabilities"`
+}
+
+// Report is pushed on every status change and at least every interval,
+// which doubles as heartbeat.
+type Report struct {
+	Status       *health.Status      `json:"status"`
+	Capabilities health.Capabilities `json:"capabilities"`
+}
+
+var (
+	errUnknownAgent = errors.New("agent not registered with portal")
+	errUnauthorized = errors.New("portal rejected credentials")
+	errForbidden    = errors.New("agent ID is registered with the portal under other credentials")
+)
+
+// Reporter registers the agent with the portal and pushes its status, so
+// agents behind NAT don't need to be reachable from the portal.
+type Reporter struct {
+	cfg    config.PortalConfig
+	cache  *health.Cache
+	info   api.Info
+	id     string
+	client *http.Client
+
+	token      string
+	registered bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewReporter(cfg config.PortalConfig, cache *health.Cache, info api.Info) *Reporter {
+	return &Reporter{
+		cfg:    cfg,
+		cache:  cache,
+		info:   info,
+		id:     agentID(),
+		client: &http.Client{Timeout: cfg.Timeout},
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+func (r *Reporter) Start() {
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(r.cfg.Interval)
+		defer ticker.Stop()
+
+		sub, _ := r.cache.Subscribe(0)
+		defer func() { sub.Close() }()
+
+		r.push()
+
+		for {
+			select {
+			case _, ok := <-sub.C:
+				if !ok {
+					// Dropped for being too slow, the next push catches up
+					sub, _ = r.cache.Subscribe(0)
+				}
+				// One status update covers all changes of a collection
+				drain(sub.C)
+				r.push()
+				ticker.Reset(r.cfg.Interval)
+
+			case <-ticker.C:
+				r.push()
+
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (r *Reporter) Stop() {
+	close(r.stop)
+	<-r.done
+}
+
+func (r *Reporter) push() {
+	status := r.cache.Get()
+	if status == nil {
+		return
+	}
+
+	if !r.registered {
+		if err := r.register(); err != nil {
+			log.Printf("portal: registration failed: %v", err)
+			return
+		}
+	}
+
+	report := Report{Status: status, Capabilities: r.cache.Capabilities()}
+	err := r.post("/api/ingest/agents/"+r.id+"/status", report)
+	if errors.Is(err, errUnknownAgent) {
+		r.registered = false
+		log.Println("portal: agent unknown to portal, registering again")
+		if err = r.register(); err == nil {
+			err = r.post("/api/ingest/agents/"+r.id+"/status", report)
+		}
+	}
+	if err != nil {
+		log.Printf("portal: status push failed: %v", err)
+	}
+}
+
+func (r *Reporter) register() error {
+	hostname, _ := os.Hostname()
+	reg := Registration{
+		AgentID:      r.id,
+		Hostname:     hostname,
+		Info:         r.info,
+		Capabilities: r.cache.Capabilities(),
+	}
+
+	if err := r.post("/api/ingest/register", reg); err != nil {
+		return err
+	}
+
+	log.Printf("portal: registered as %s with %s", r.id, r.cfg.URL)
+	r.registered = true
+	return nil
+}
+
+// post sends body as JSON, retrying transient failures with exponential
+// backoff starting at RetryDelay.
+func (r *Reporter) post(path string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	delay := r.cfg.RetryDelay
+	for attempt := 0; ; attempt++ {
+		err = r.do(path, data)
+		if err == nil || errors.Is(err, errUnknownAgent) || errors.Is(err, errForbidden) || attempt >= r.cfg.RetryAttempts {
+			return err
+		}
+		if errors.Is(err, errUnauthorized) && r.cfg.APIKey != "" {
+			// A wrong API key won't get better by retrying
+			return err
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-r.stop:
+			return err
+		}
+		delay *= 2
+	}
+}
+
+func (r *Reporter) do(path string, data []byte) error {
+	token, err := r.authToken()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.url(path), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		// A login token may have expired, log in again on the next attempt
+		r.token = ""
+		return errUnauthorized
+	case resp.StatusCode == http.StatusForbidden:
+		return errForbidden
+	case resp.StatusCode == http.StatusNotFound:
+		return errUnknownAgent
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("portal returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
+
+// authToken returns the API key, or logs in with the credentials to get
+// a session token.
+func (r *Reporter) authToken() (string, error) {
+	if r.cfg.APIKey != "" {
+		return r.cfg.APIKey, nil
+	}
+	if r.token != "" {
+		return r.token, nil
+	}
+
+	data, _ := json.Marshal(map[string]string{
+		"username": r.cfg.Credentials.Username,
+		"password": r.cfg.Credentials.Password,
+	})
+
+	resp, err := r.client.Post(r.url("/api/auth/login"), "application/json", bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("login: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("login: portal returned %d", resp.StatusCode)
+	}
+
+	var login struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&login); err != nil {
+		return "", fmt.Errorf("login: %w", err)
+	}
+
+	r.token = login.Token
+	return r.token, nil
+}
+
+func (r *Reporter) url(path string) string {
+	return strings.TrimSuffix(r.cfg.URL, "/") + path
+}
+
+func drain(c <-chan health.Change) {
+	for {
+		select {
+		case _, ok := <-c:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// agentID identifies the machine across restarts and hostname changes.
+func agentID() string {
+	for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		if data, err := os.ReadFile(path); err == nil {
+			if id := strings.TrimSpace(string(data)); id != "" {
+				return id
+			}
+		}
+	}
+	hostname, _ := os.Hostname()
+	return hostname
+}
@@ -0,0 +1,314 @@
+package recordings
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+)
+
+// Containers
+const (
+	ContainerMatroska = "matroska"
+	ContainerWebM     = "webm"
+	ContainerMP4      = "mp4"
+	ContainerMOV      = "mov"
+	ContainerFLV      = "flv"
+	ContainerMPEGTS   = "mpegts"
+)
+
+// Media is what could be read from a file's header. Duration is missing
+// for formats that don't store it and for recordings still in progress,
+// which OBS only finalizes when it stops.
+type Media struct {
+	Container       string  `json:"container,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds,omitempty"`
+}
+
+func probeFile(root *os.Root, name string, size int64) Media {
+	f, err := root.Open(name)
+	if err != nil {
+		return Media{}
+	}
+	defer f.Close()
+
+	return probe(f, size)
+}
+
+func probe(r io.ReaderAt, size int64) Media {
+	head := make([]byte, 12)
+	n, _ := r.ReadAt(head, 0)
+	head = head[:n]
+
+	switch {
+	case bytes.HasPrefix(head, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return probeMatroska(r, size)
+	case len(head) >= 8 && string(head[4:8]) == "ftyp":
+		return probeMP4(r, size)
+	case bytes.HasPrefix(head, []byte("FLV")):
+		return probeFLV(r)
+	case len(head) > 0 && head[0] == 0x47 && isMPEGTS(r):
+		return Media{Container: ContainerMPEGTS}
+	}
+
+	return Media{}
+}
+
+// probeMP4 walks the top-level boxes to moov/mvhd. OBS writes moov at
+// the end, so the boxes in between are skipped, not read.
+func probeMP4(r io.ReaderAt, size int64) Media {
+	media := Media{Container: ContainerMP4}
+
+	for off := int64(0); off+8 <= size; {
+		boxSize, typ, hdrLen, ok := readBox(r, off, size)
+		if !ok {
+			break
+		}
+
+		switch typ {
+		case "ftyp":
+			brand := make([]byte, 4)
+			if _, err := r.ReadAt(brand, off+hdrLen); err == nil && string(brand) == "qt  " {
+				media.Container = ContainerMOV
+			}
+		case "moov":
+			media.DurationSeconds = mvhdDuration(r, off+hdrLen, off+boxSize)
+			return media
+		}
+
+		off += boxSize
+	}
+
+	return media
+}
+
+func mvhdDuration(r io.ReaderAt, off, end int64) float64 {
+	for off+8 <= end {
+		boxSize, typ, hdrLen, ok := readBox(r, off, end)
+		if !ok {
+			return 0
+		}
+		if typ != "mvhd" {
+			off += boxSize
+			continue
+		}
+
+		buf := make([]byte, 32)
+		if _, err := r.ReadAt(buf, off+hdrLen); err != nil && err != io.EOF {
+			return 0
+		}
+
+		var timescale uint32
+		var duration uint64
+		if buf[0] == 1 {
+			timescale = binary.BigEndian.Uint32(buf[20:24])
+			duration = binary.BigEndian.Uint64(buf[24:32])
+		} else {
+			timescale = binary.BigEndian.Uint32(buf[12:16])
+			duration = uint64(binary.BigEndian.Uint32(buf[16:20]))
+		}
+
+		// All ones means unknown
+		if timescale == 0 || duration == math.MaxUint32 || duration == math.MaxUint64 {
+			return 0
+		}
+		return round(float64(duration) / float64(timescale))
+	}
+	return 0
+}
+
+// readBox reads an ISO BMFF box header at off.
+func readBox(r io.ReaderAt, off, end int64) (size int64, typ string, hdrLen int64, ok bool) {
+	hdr := make([]byte, 16)
+	if n, _ := r.ReadAt(hdr, off); n < 8 {
+		return 0, "", 0, false
+	}
+
+	size, typ, hdrLen = int64(binary.BigEndian.Uint32(hdr[0:4])), string(hdr[4:8]), 8
+	switch size {
+	case 0:
+		size = end - off
+	case 1:
+		size, hdrLen = int64(binary.BigEndian.Uint64(hdr[8:16])), 16
+	}
+
+	if size < hdrLen || off+size > end {
+		return 0, "", 0, false
+	}
+	return size, typ, hdrLen, true
+}
+
+// Matroska element IDs
+const (
+	ebmlDocType       = 0x4282
+	mkvSegment        = 0x18538067
+	mkvInfo           = 0x1549A966
+	mkvTimecodeScale  = 0x2AD7B1
+	mkvDuration       = 0x4489
+	mkvCluster        = 0x1F43B675
+	maxMatroskaChecks = 64
+)
+
+// probeMatroska reads the EBML header for the doc type and the segment
+// info for the duration, stopping at the first cluster.
+func probeMatroska(r io.ReaderAt, size int64) Media {
+	media := Media{Container: ContainerMatroska}
+
+	_, hdrSize, n, ok := readElement(r, 0)
+	if !ok || hdrSize == unknownSize {
+		return media
+	}
+	hdrEnd := n + int64(hdrSize)
+
+	for off := n; off < hdrEnd; {
+		id, dataSize, n, ok := readElement(r, off)
+		if !ok || dataSize == unknownSize {
+			break
+		}
+		if id == ebmlDocType {
+			docType := make([]byte, min(dataSize, 16))
+			if _, err := r.ReadAt(docType, off+n); err == nil && string(bytes.TrimRight(docType, "\x00")) == "webm" {
+				media.Container = ContainerWebM
+			}
+		}
+		off += n + int64(dataSize)
+	}
+
+	id, _, n, ok := readElement(r, hdrEnd)
+	if !ok || id != mkvSegment {
+		return media
+	}
+
+	off := hdrEnd + n
+	for i := 0; i < maxMatroskaChecks && off < size; i++ {
+		id, dataSize, n, ok := readElement(r, off)
+		if !ok || id == mkvCluster || dataSize == unknownSize {
+			break
+		}
+		if id == mkvInfo {
+			media.DurationSeconds = matroskaDuration(r, off+n, off+n+int64(dataSize))
+			break
+		}
+		off += n + int64(dataSize)
+	}
+
+	return media
+}
+
+func matroskaDuration(r io.ReaderAt, off, end int64) float64 {
+	scale := uint64(1000000)
+	var duration float64
+
+	for off < end {
+		id, dataSize, n, ok := readElement(r, off)
+		if !ok || dataSize == unknownSize {
+			break
+		}
+
+		if (id == mkvTimecodeScale || id == mkvDuration) && dataSize <= 8 {
+			buf := make([]byte, dataSize)
+			if _, err := r.ReadAt(buf, off+n); err != nil {
+				break
+			}
+
+			switch {
+			case id == mkvTimecodeScale:
+				scale = 0
+				for _, b := range buf {
+					scale = scale<<8 | uint64(b)
+				}
+			case dataSize == 4:
+				duration = float64(math.Float32frombits(binary.BigEndian.Uint32(buf)))
+			case dataSize == 8:
+				duration = math.Float64frombits(binary.BigEndian.Uint64(buf))
+			}
+		}
+
+		off += n + int64(dataSize)
+	}
+
+	return round(duration * float64(scale) / 1e9)
+}
+
+const unknownSize = math.MaxUint64
+
+// readElement reads an EBML element ID and data size at off and returns
+// the header length.
+func readElement(r io.ReaderAt, off int64) (id uint32, size uint64, n int64, ok bool) {
+	buf := make([]byte, 12)
+	if read, _ := r.ReadAt(buf, off); read < 2 {
+		return 0, 0, 0, false
+	}
+
+	idLen := vintLength(buf[0])
+	if idLen == 0 || idLen > 4 {
+		return 0, 0, 0, false
+	}
+	for _, b := range buf[:idLen] {
+		id = id<<8 | uint32(b)
+	}
+
+	sizeLen := vintLength(buf[idLen])
+	if sizeLen == 0 {
+		return 0, 0, 0, false
+	}
+	// The length marker bit isn't part of the value
+	size = uint64(buf[idLen]) & (0xFF >> sizeLen)
+	allOnes := size == 0xFF>>sizeLen
+	for _, b := range buf[idLen+1 : idLen+sizeLen] {
+		size = size<<8 | uint64(b)
+		allOnes = allOnes && b == 0xFF
+	}
+	if allOnes {
+		size = unknownSize
+	}
+
+	return id, size, int64(idLen + sizeLen), true
+}
+
+func vintLength(b byte) int {
+	for i := 0; i < 8; i++ {
+		if b&(0x80>>i) != 0 {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// probeFLV takes the duration from the onMetaData tag, which OBS updates
+// when the recording ends. Instead of decoding AMF we look for the
+// "duration" key followed by a number marker.
+func probeFLV(r io.ReaderAt) Media {
+	media := Media{Container: ContainerFLV}
+
+	buf := make([]byte, 4096)
+	n, _ := r.ReadAt(buf, 0)
+	buf = buf[:n]
+
+	key := []byte("\x00\x08duration\x00")
+	if i := bytes.Index(buf, key); i >= 0 && i+len(key)+8 <= len(buf) {
+		start := i + len(key)
+		media.DurationSeconds = round(math.Float64frombits(binary.BigEndian.Uint64(buf[start : start+8])))
+	}
+
+	return media
+}
+
+// isMPEGTS checks for the sync byte at the start of the first packets.
+func isMPEGTS(r io.ReaderAt) bool {
+	b := make([]byte, 1)
+	for i := int64(0); i < 3; i++ {
+		if _, err := r.ReadAt(b, i*188); err != nil || b[0] != 0x47 {
+			return false
+		}
+	}
+	return true
+}
+
+func round(seconds float64) float64 {
+	if seconds < 0 || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return 0
+	}
+	return math.Round(seconds*1000) / 1000
+}
@@ -0,0 +1,177 @@
+package recordings
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// box builds an ISO BMFF box.
+func box(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	b := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	return append(append(b, typ...), body...)
+}
+
+// mvhd builds a version 0 movie header.
+func mvhd(timescale, duration uint32) []byte {
+	body := make([]byte, 100)
+	binary.BigEndian.PutUint32(body[12:], timescale)
+	binary.BigEndian.PutUint32(body[16:], duration)
+	return box("mvhd", body)
+}
+
+// mvhd1 builds a version 1 movie header with 64-bit times.
+func mvhd1(timescale uint32, duration uint64) []byte {
+	body := make([]byte, 112)
+	body[0] = 1
+	binary.BigEndian.PutUint32(body[20:], timescale)
+	binary.BigEndian.PutUint64(body[24:], duration)
+	return box("mvhd", body)
+}
+
+// element builds an EBML element with a 4-byte size. id is written
+// with its length marker, as in the spec tables.
+func element(id uint32, data ...[]byte) []byte {
+	var b []byte
+	switch {
+	case id > 0xFFFFFF:
+		b = binary.BigEndian.AppendUint32(nil, id)
+	case id > 0xFFFF:
+		b = []byte{byte(id >> 16), byte(id >> 8), byte(id)}
+	case id > 0xFF:
+		b = []byte{byte(id >> 8), byte(id)}
+	default:
+		b = []byte{byte(id)}
+	}
+	body := bytes.Join(data, nil)
+	b = append(b, 0x10|byte(len(body)>>24), byte(len(body)>>16), byte(len(body)>>8), byte(len(body)))
+	return append(b, body...)
+}
+
+// unknownSizeElement builds an element header with an unknown size, as
+// written for live streams and unfinished recordings.
+func unknownSizeElement(id uint32) []byte {
+	return append(binary.BigEndian.AppendUint32(nil, id), 0xFF)
+}
+
+func float64Bytes(v float64) []byte {
+	return binary.BigEndian.AppendUint64(nil, math.Float64bits(v))
+}
+
+func float32Bytes(v float32) []byte {
+	return binary.BigEndian.AppendUint32(nil, math.Float32bits(v))
+}
+
+const ebmlHeader = 0x1A45DFA3
+
+func matroska(docType string, info ...[]byte) []byte {
+	header := element(ebmlHeader, element(ebmlDocType, []byte(docType)))
+	segment := element(mkvSegment, element(mkvInfo, info...), element(mkvCluster, []byte{0}))
+	return append(header, segment...)
+}
+
+// flv builds an FLV header followed by an onMetaData script tag.
+func flv(duration float64) []byte {
+	data := []byte("FLV\x01\x05\x00\x00\x00\x09\x00\x00\x00\x00")
+	data = append(data, 0x12, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0)
+	data = append(data, 0x02, 0x00, 0x0a)
+	data = append(data, "onMetaData"...)
+	data = append(data, 0x08, 0, 0, 0, 2)
+	data = append(data, 0x00, 0x08)
+	data = append(data, "duration"...)
+	data = append(data, 0x00)
+	data = append(data, float64Bytes(duration)...)
+	data = append(data, 0x00, 0x05)
+	data = append(data, "width"...)
+	data = append(data, 0x00)
+	return append(data, float64Bytes(1920)...)
+}
+
+func TestProbe(t *testing.T) {
+	mdat := box("mdat", make([]byte, 4096))
+	ts := bytes.Repeat(append([]byte{0x47}, make([]byte, 187)...), 4)
+
+	tests := []struct {
+		name string
+		data []byte
+		want Media
+	}{
+		{
+			name: "mp4 with moov at the end",
+			data: bytes.Join([][]byte{box("ftyp", []byte("isom\x00\x00\x02\x00isomiso2avc1mp41")), mdat, box("moov", mvhd(1000, 754321))}, nil),
+			want: Media{Container: ContainerMP4, DurationSeconds: 754.321},
+		},
+		{
+			name: "mov with a version 1 header",
+			data: bytes.Join([][]byte{box("ftyp", []byte("qt  \x00\x00\x02\x00qt  ")), box("moov", box("trak"), mvhd1(90000, 90000*3600))}, nil),
+			want: Media{Container: ContainerMOV, DurationSeconds: 3600},
+		},
+		{
+			name: "mp4 still recording",
+			data: bytes.Join([][]byte{box("ftyp", []byte("isom")), mdat}, nil),
+			want: Media{Container: ContainerMP4},
+		},
+		{
+			name: "mp4 with unknown duration",
+			data: bytes.Join([][]byte{box("ftyp", []byte("isom")), box("moov", mvhd(1000, math.MaxUint32))}, nil),
+			want: Media{Container: ContainerMP4},
+		},
+		{
+			name: "mp4 truncated box",
+			data: append(box("ftyp", []byte("isom")), 0, 0, 0x10, 0, 'm', 'o', 'o', 'v'),
+			want: Media{Container: ContainerMP4},
+		},
+		{
+			name: "matroska with float64 duration",
+			data: matroska("matroska", element(mkvTimecodeScale, []byte{0x0F, 0x42, 0x40}), element(mkvDuration, float64Bytes(125500))),
+			want: Media{Container: ContainerMatroska, DurationSeconds: 125.5},
+		},
+		{
+			name: "webm with float32 duration and a custom scale",
+			data: matroska("webm", element(mkvTimecodeScale, []byte{0x27, 0x10}), element(mkvDuration, float32Bytes(500000))),
+			want: Media{Container: ContainerWebM, DurationSeconds: 5},
+		},
+		{
+			name: "matroska still recording",
+			data: append(element(ebmlHeader, element(ebmlDocType, []byte("matroska"))), unknownSizeElement(mkvSegment)...),
+			want: Media{Container: ContainerMatroska},
+		},
+		{
+			name: "flv",
+			data: flv(61.25),
+			want: Media{Container: ContainerFLV, DurationSeconds: 61.25},
+		},
+		{
+			name: "flv without metadata",
+			data: []byte("FLV\x01\x05\x00\x00\x00\x09\x00\x00\x00\x00"),
+			want: Media{Container: ContainerFLV},
+		},
+		{
+			name: "mpeg-ts",
+			data: ts,
+			want: Media{Container: ContainerMPEGTS},
+		},
+		{
+			name: "single sync byte",
+			data: append([]byte{0x47}, make([]byte, 400)...),
+		},
+		{
+			name: "unknown",
+			data: []byte("RIFF\x00\x00\x00\x00WAVEfmt "),
+		},
+		{
+			name: "empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := probe(bytes.NewReader(tt.data), int64(len(tt.data)))
+			if got != tt.want {
+				t.Errorf("probe() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package recordings
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"kit.workmate/live-agent/internal/config"
+	"kit.workmate/live-agent/internal/system/load"
+)
+
+var (
+	ErrUnknownDirectory = errors.New("unknown recording directory")
+	ErrNotAFile         = errors.New("not a regular file")
+)
+
+// Directory is a configured recording directory with its free space.
+type Directory struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	TotalBytes uint64 `json:"total_bytes"`
+	FreeBytes  uint64 `json:"free_bytes"`
+	Error      string `json:"error,omitempty"`
+}
+
+type File struct {
+	// Name is relative to the directory, with forward slashes
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+	Media
+}
+
+type Listing struct {
+	Directory
+	Files []File `json:"files"`
+}
+
+// Library serves the files of the configured recording directories. All
+// access goes through os.Root, so neither "../" nor a symlink can reach
+// outside of them.
+type Library struct {
+	mu   sync.Mutex
+	dirs []config.RecordingDirConfig
+
+	// media caches probe results per directory, keyed by file name and
+	// invalidated by size and mtime
+	media map[string]map[string]cachedMedia
+}
+
+type cachedMedia struct {
+	size    int64
+	modTime time.Time
+	media   Media
+}
+
+func New(cfg config.RecordingsConfig) *Library {
+	l := &Library{}
+	l.Update(cfg)
+	return l
+}
+
+// Update replaces the directories, e.g. after a config reload.
+func (l *Library) Update(cfg config.RecordingsConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.dirs = cfg.Directories
+	l.media = map[string]map[string]cachedMedia{}
+}
+
+func (l *Library) Directories() []Directory {
+	l.mu.Lock()
+	dirs := l.dirs
+	l.mu.Unlock()
+
+	result := make([]Directory, 0, len(dirs))
+	for _, dir := range dirs {
+		result = append(result, directory(dir))
+	}
+	return result
+}
+
+// List returns the files below the directory, newest first. Hidden files
+// and directories are skipped.
+func (l *Library) List(name string) (*Listing, error) {
+	dir, err := l.dir(name)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := os.OpenRoot(dir.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	l.mu.Lock()
+	cache := l.media[name]
+	l.mu.Unlock()
+
+	seen := map[string]cachedMedia{}
+	files := []File{}
+
+	err = fs.WalkDir(root.FS(), ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable subdirectory, list the rest
+			return nil
+		}
+		if p != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		cached, ok := cache[p]
+		if !ok || cached.size != info.Size() || !cached.modTime.Equal(info.ModTime()) {
+			cached = cachedMedia{size: info.Size(), modTime: info.ModTime(), media: probeFile(root, p, info.Size())}
+		}
+		seen[p] = cached
+
+		files = append(files, File{
+			Name:    p,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+			Media:   cached.media,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	if l.media != nil {
+		l.media[name] = seen
+	}
+	l.mu.Unlock()
+
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime.After(files[j].ModTime) })
+
+	return &Listing{Directory: directory(dir), Files: files}, nil
+}
+
+// Open opens a file for download. The caller closes it.
+func (l *Library) Open(name, file string) (*os.File, error) {
+	root, err := l.openRoot(name, file)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	f, err := root.Open(file)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", file, ErrNotAFile)
+	}
+
+	return f, nil
+}
+
+// Delete removes a file. Directories are never removed.
+func (l *Library) Delete(name, file string) error {
+	root, err := l.openRoot(name, file)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	info, err := root.Lstat(file)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s: %w", file, ErrNotAFile)
+	}
+
+	return root.Remove(file)
+}
+
+func (l *Library) dir(name string) (config.RecordingDirConfig, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, dir := range l.dirs {
+		if dir.Name == name {
+			return dir, nil
+		}
+	}
+	return config.RecordingDirConfig{}, fmt.Errorf("%w: %s", ErrUnknownDirectory, name)
+}
+
+// openRoot checks the file name before the root does, so "../x" is
+// rejected as invalid rather than reported as an I/O error.
+func (l *Library) openRoot(name, file string) (*os.Root, error) {
+	dir, err := l.dir(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !fs.ValidPath(file) || file == "." {
+		return nil, &fs.PathError{Op: "open", Path: file, Err: fs.ErrInvalid}
+	}
+	// Hidden files aren't listed, so they can't be fetched either
+	for _, part := range strings.Split(file, "/") {
+		if strings.HasPrefix(part, ".") {
+			return nil, &fs.PathError{Op: "open", Path: file, Err: fs.ErrNotExist}
+		}
+	}
+
+	return os.OpenRoot(dir.Path)
+}
+
+func directory(dir config.RecordingDirConfig) Directory {
+	disk := load.DiskUsage(dir.Path)
+	return Directory{
+		Name:       dir.Name,
+		Path:       dir.Path,
+		TotalBytes: disk.TotalBytes,
+		FreeBytes:  disk.FreeBytes,
+		Error:      disk.Error,
+	}
+}
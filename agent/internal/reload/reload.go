@@ -0,0 +1,181 @@
+package reload
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"kit.workmate/live-agent/internal/config"
+)
+
+// ApplyFunc switches the running agent from old to cfg. It returns the
+// settings that changed but only take effect after a restart. On error
+// the old config stays active.
+type ApplyFunc func(old, cfg *config.Config) (restartRequired []string, err error)
+
+// Status describes the active config and the outcome of the last reload.
+type Status struct {
+	Path            string     `json:"path,omitempty"`
+	Version         int        `json:"version"`
+	Checksum        string     `json:"checksum,omitempty"`
+	LoadedAt        time.Time  `json:"loaded_at"`
+	LastError       string     `json:"last_error,omitempty"`
+	LastErrorAt     *time.Time `json:"last_error_at,omitempty"`
+	RestartRequired []string   `json:"restart_required,omitempty"`
+}
+
+var ErrNoConfigFile = errors.New("running on defaults, no config file to reload")
+
+// Reloader holds the active config and replaces it when the file changes.
+type Reloader struct {
+	path  string
+	apply ApplyFunc
+
+	// reload serializes reloads, mu guards the fields below
+	reload  sync.Mutex
+	mu      sync.RWMutex
+	current *config.Config
+	status  Status
+
+	stop chan struct{}
+}
+
+func New(path string, cfg *config.Config, apply ApplyFunc) *Reloader {
+	r := &Reloader{
+		path:    path,
+		apply:   apply,
+		current: cfg,
+		status: Status{
+			Path:     path,
+			Version:  1,
+			LoadedAt: time.Now(),
+		},
+		stop: make(chan struct{}),
+	}
+
+	if path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			r.status.Checksum = checksum(data)
+		}
+	}
+
+	return r
+}
+
+func (r *Reloader) Config() *config.Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current
+}
+
+func (r *Reloader) Status() Status {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.status
+}
+
+// Reload reads and applies the config file. Unchanged files are skipped.
+func (r *Reloader) Reload() error {
+	r.reload.Lock()
+	defer r.reload.Unlock()
+
+	if r.path == "" {
+		return ErrNoConfigFile
+	}
+
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return r.fail(err)
+	}
+
+	sum := checksum(data)
+	if sum == r.Status().Checksum {
+		return nil
+	}
+
+	cfg, err := config.Parse(data)
+	if err != nil {
+		return r.fail(err)
+	}
+
+	restartRequired, err := r.apply(r.Config(), cfg)
+	if err != nil {
+		return r.fail(err)
+	}
+
+	r.mu.Lock()
+	r.current = cfg
+	r.status.Version++
+	r.status.Checksum = sum
+	r.status.LoadedAt = time.Now()
+	r.status.LastError = ""
+	r.status.LastErrorAt = nil
+	r.status.RestartRequired = restartRequired
+	version := r.status.Version
+	r.mu.Unlock()
+
+	log.Printf("config: loaded version %d from %s", version, r.path)
+	for _, setting := range restartRequired {
+		log.Printf("config: %s changed, takes effect after a restart", setting)
+	}
+	return nil
+}
+
+func (r *Reloader) fail(err error) error {
+	now := time.Now()
+
+	r.mu.Lock()
+	r.status.LastError = err.Error()
+	r.status.LastErrorAt = &now
+	r.mu.Unlock()
+
+	log.Printf("config: reload failed, keeping version %d: %v", r.Status().Version, err)
+	return err
+}
+
+// Watch reloads whenever the file's modification time or size changes.
+// Stat polling also catches editors that replace the file on save.
+func (r *Reloader) Watch(interval time.Duration) {
+	if r.path == "" {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		last, _ := os.Stat(r.path)
+
+		for {
+			select {
+			case <-ticker.C:
+				info, err := os.Stat(r.path)
+				if err != nil {
+					// Possibly mid-replace, try again on the next tick
+					continue
+				}
+				if last != nil && info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size() {
+					continue
+				}
+				last = info
+				_ = r.Reload()
+
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (r *Reloader) Stop() {
+	close(r.stop)
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
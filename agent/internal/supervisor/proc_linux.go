@@ -0,0 +1,15 @@
+//go:build linux
+
+package supervisor
+
+import "syscall"
+
+func sysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: true}
+}
+
+// signalGroup signals OBS and its children, e.g. the sandbox of a
+// Flatpak install.
+func signalGroup(pid int, sig syscall.Signal) error {
+	return syscall.Kill(-pid, sig)
+}
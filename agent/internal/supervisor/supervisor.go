@@ -0,0 +1,380 @@
+package supervisor
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"kit.workmate/live-agent/internal/config"
+	"kit.workmate/live-agent/internal/system/obs"
+)
+
+// Supervisor states
+const (
+	StateStopped  = "stopped"
+	StateRunning  = "running"
+	StateStopping = "stopping"
+	StateBackoff  = "backoff" // waiting to restart after a crash
+	StateFailed   = "failed"  // gave up after restart.max_attempts
+)
+
+var (
+	ErrDisabled       = errors.New("OBS supervisor is disabled")
+	ErrAlreadyRunning = errors.New("OBS is already running")
+	ErrNotRunning     = errors.New("OBS is not running")
+)
+
+// Exit describes how an OBS process ended. Crash is false for exits the
+// supervisor asked for and for a clean exit, e.g. closing OBS by hand.
+type Exit struct {
+	Time          time.Time `json:"time"`
+	PID           int       `json:"pid"`
+	ExitCode      int       `json:"exit_code"`
+	Signal        string    `json:"signal,omitempty"`
+	UptimeSeconds float64   `json:"uptime_seconds"`
+	Crash         bool      `json:"crash"`
+}
+
+type Status struct {
+	Enabled bool   `json:"enabled"`
+	State   string `json:"state"`
+	// Managed is true if the agent started the running OBS
+	Managed   bool       `json:"managed"`
+	PID       int        `json:"pid,omitempty"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	Command   []string   `json:"command"`
+
+	// Restarts counts automatic restarts since the last manual start
+	Restarts    int        `json:"restarts"`
+	NextRestart *time.Time `json:"next_restart,omitempty"`
+	LastExit    *Exit      `json:"last_exit,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	Crashes     []Exit     `json:"crashes"`
+}
+
+// Supervisor starts and stops OBS and restarts it after a crash. OBS
+// started by someone else is left alone, except that Stop ends it.
+type Supervisor struct {
+	// probe finds an OBS the supervisor didn't start
+	probe func() (obs.Status, error)
+
+	mu        sync.Mutex
+	cfg       config.OBSConfig
+	state     string
+	cmd       *exec.Cmd
+	done      chan struct{}
+	startedAt time.Time
+	stopping  bool
+
+	attempts    int // crashes since OBS last ran stable
+	restarts    int
+	timer       *time.Timer
+	nextRestart time.Time
+	lastExit    *Exit
+	lastErr     string
+	crashes     []Exit
+	closed      bool
+}
+
+func New(cfg config.OBSConfig) *Supervisor {
+	return &Supervisor{
+		probe: obs.NewProber("/proc").Probe,
+		cfg:   cfg,
+		state: StateStopped,
+	}
+}
+
+// Update applies a reloaded config. A changed command line is used from
+// the next start on; disabling the supervisor cancels a pending restart
+// but leaves OBS running.
+func (s *Supervisor) Update(cfg config.OBSConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cfg = cfg
+	if !cfg.Supervise || !cfg.Restart.Enabled {
+		s.cancelRestartLocked()
+	}
+}
+
+// Start launches OBS. It fails if OBS is already running, whoever
+// started it.
+func (s *Supervisor) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.cfg.Supervise {
+		return ErrDisabled
+	}
+
+	s.cancelRestartLocked()
+	s.attempts, s.restarts = 0, 0
+	return s.startLocked()
+}
+
+// Stop asks OBS to quit with SIGTERM, so it can finish recordings and
+// save its config, and kills it after stop_timeout.
+func (s *Supervisor) Stop() error {
+	s.mu.Lock()
+	if !s.cfg.Supervise {
+		s.mu.Unlock()
+		return ErrDisabled
+	}
+
+	pending := s.cancelRestartLocked()
+	cmd, done, timeout := s.cmd, s.done, s.cfg.StopTimeout
+
+	if cmd == nil {
+		probed, err := s.probe()
+		s.mu.Unlock()
+
+		if err != nil {
+			return err
+		}
+		if !probed.Running || probed.Process == nil {
+			if pending {
+				return nil
+			}
+			return ErrNotRunning
+		}
+		return stopExternal(probed.Process.PID, timeout)
+	}
+
+	s.stopping = true
+	s.state = StateStopping
+	s.mu.Unlock()
+
+	_ = signalGroup(cmd.Process.Pid, syscall.SIGTERM)
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log.Printf("OBS did not exit within %s, killing it", timeout)
+		_ = signalGroup(cmd.Process.Pid, syscall.SIGKILL)
+		<-done
+	}
+	return nil
+}
+
+// Restart stops OBS if it is running and starts it again.
+func (s *Supervisor) Restart() error {
+	if err := s.Stop(); err != nil && !errors.Is(err, ErrNotRunning) {
+		return err
+	}
+	return s.Start()
+}
+
+// Close cancels a pending restart. OBS keeps running when the agent exits.
+func (s *Supervisor) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	s.cancelRestartLocked()
+}
+
+func (s *Supervisor) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := Status{
+		Enabled:   s.cfg.Supervise,
+		State:     s.state,
+		Command:   s.cfg.Args(),
+		Restarts:  s.restarts,
+		LastExit:  s.lastExit,
+		LastError: s.lastErr,
+		Crashes:   append([]Exit{}, s.crashes...),
+	}
+
+	if s.cmd != nil {
+		startedAt := s.startedAt
+		status.Managed = true
+		status.PID = s.cmd.Process.Pid
+		status.StartedAt = &startedAt
+	} else if s.state == StateStopped {
+		if probed, err := s.probe(); err == nil && probed.Running {
+			status.State = StateRunning
+			if probed.Process != nil {
+				status.PID = probed.Process.PID
+				status.StartedAt = &probed.Process.StartedAt
+			}
+		}
+	}
+
+	if s.state == StateBackoff {
+		next := s.nextRestart
+		status.NextRestart = &next
+	}
+
+	return status
+}
+
+func (s *Supervisor) startLocked() error {
+	if s.cmd != nil {
+		return ErrAlreadyRunning
+	}
+	probed, err := s.probe()
+	if err != nil {
+		return fmt.Errorf("looking for a running OBS: %w", err)
+	}
+	if probed.Running {
+		if probed.Process != nil {
+			return fmt.Errorf("%w (pid %d, not started by the agent)", ErrAlreadyRunning, probed.Process.PID)
+		}
+		return ErrAlreadyRunning
+	}
+
+	args := s.cfg.Args()
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), s.cfg.Env...)
+	// Own process group, so a Ctrl+C meant for the agent doesn't reach OBS
+	cmd.SysProcAttr = sysProcAttr()
+
+	if err := cmd.Start(); err != nil {
+		s.lastErr = err.Error()
+		return fmt.Errorf("starting OBS: %w", err)
+	}
+
+	s.cmd = cmd
+	s.done = make(chan struct{})
+	s.startedAt = time.Now()
+	s.state = StateRunning
+	s.lastErr = ""
+	log.Printf("started OBS (pid %d): %v", cmd.Process.Pid, args)
+
+	go s.wait(cmd, s.done)
+	return nil
+}
+
+func (s *Supervisor) wait(cmd *exec.Cmd, done chan struct{}) {
+	_ = cmd.Wait()
+	defer close(done)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := cmd.ProcessState
+	exit := Exit{
+		Time:          time.Now(),
+		PID:           state.Pid(),
+		ExitCode:      state.ExitCode(),
+		UptimeSeconds: time.Since(s.startedAt).Seconds(),
+		Crash:         !s.stopping && !state.Success(),
+	}
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		exit.Signal = ws.Signal().String()
+	}
+
+	s.cmd = nil
+	s.stopping = false
+	s.lastExit = &exit
+	s.state = StateStopped
+
+	if !exit.Crash {
+		log.Printf("OBS (pid %d) exited", exit.PID)
+		return
+	}
+
+	log.Printf("OBS (pid %d) crashed after %.0fs: %s", exit.PID, exit.UptimeSeconds, state)
+	s.crashes = append(s.crashes, exit)
+	if over := len(s.crashes) - s.cfg.HistorySize; over > 0 {
+		s.crashes = s.crashes[over:]
+	}
+
+	if time.Since(s.startedAt) >= s.cfg.Restart.StableAfter {
+		s.attempts = 0
+	}
+	s.scheduleRestartLocked()
+}
+
+// scheduleRestartLocked counts a failed run and restarts OBS after the
+// backoff, if restarts are enabled and attempts are left.
+func (s *Supervisor) scheduleRestartLocked() {
+	restart := s.cfg.Restart
+	if !s.cfg.Supervise || !restart.Enabled || s.closed {
+		return
+	}
+
+	s.attempts++
+	if restart.MaxAttempts > 0 && s.attempts > restart.MaxAttempts {
+		log.Printf("OBS crashed %d times in a row, not restarting it", s.attempts)
+		s.state = StateFailed
+		return
+	}
+
+	delay := restart.Backoff
+	for i := 1; i < s.attempts && delay < restart.MaxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, restart.MaxBackoff)
+
+	s.state = StateBackoff
+	s.nextRestart = time.Now().Add(delay)
+	s.timer = time.AfterFunc(delay, s.restartAfterCrash)
+	log.Printf("restarting OBS in %s", delay)
+}
+
+func (s *Supervisor) restartAfterCrash() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Cancelled in the meantime
+	if s.state != StateBackoff {
+		return
+	}
+	s.timer = nil
+	s.state = StateStopped
+
+	if err := s.startLocked(); err != nil {
+		log.Printf("restarting OBS: %v", err)
+		if errors.Is(err, ErrAlreadyRunning) {
+			// Someone else started it, that's fine too
+			return
+		}
+		s.scheduleRestartLocked()
+		return
+	}
+	s.restarts++
+}
+
+// cancelRestartLocked drops a pending or given-up restart and reports
+// whether there was one.
+func (s *Supervisor) cancelRestartLocked() bool {
+	if s.state != StateBackoff && s.state != StateFailed {
+		return false
+	}
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.state = StateStopped
+	return true
+}
+
+// stopExternal ends an OBS the supervisor didn't start. It isn't our
+// child, so the only way to see it exit is to poll.
+func stopExternal(pid int, timeout time.Duration) error {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if err := proc.Signal(syscall.SIGTERM); err != nil {
+		return fmt.Errorf("stopping OBS (pid %d): %w", pid, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if proc.Signal(syscall.Signal(0)) != nil {
+			return nil
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	log.Printf("OBS (pid %d) did not exit within %s, killing it", pid, timeout)
+	return proc.Kill()
+}
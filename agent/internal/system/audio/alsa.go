@@ -0,0 +1,192 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"kit.workmate/live-agent/internal/system/devid"
+	"kit.workmate/live-agent/internal/system/usb"
+)
+
+// Card ist eine ALSA-Soundkarte aus /proc/asound/cards.
+type Card struct {
+	Index  int    `json:"index"`
+	ID     string `json:"id"`
+	Driver string `json:"driver"`
+	Name   string `json:"name"`
+
+	// Identity bleibt gleich, auch wenn sich der Index nach dem
+	// Umstecken ändert
+	Identity devid.Identity `json:"identity"`
+}
+
+// devSnd und sysClassSound liefern die stabilen Namen der Karten.
+var (
+	devSnd        = "/dev/snd"
+	sysClassSound = "/sys/class/sound"
+)
+
+// alsa liest Karten und PCM-Geräte direkt aus /proc/asound.
+// Das ist der Fallback für Systeme ohne Soundserver.
+type alsa struct {
+	root string
+}
+
+func (alsa) Name() string {
+	return "alsa"
+}
+
+func (a alsa) Detect() bool {
+	cards, err := a.cards()
+	return err == nil && len(cards) > 0
+}
+
+// Probe liest Karten und PCM-Geräte. Ohne /proc/asound (kein Soundtreiber
+// geladen) gibt es einfach keine, nur ein unlesbares ist ein Fehler.
+func (a alsa) Probe() (Status, error) {
+	status := Status{
+		Backend: a.Name(),
+		Sinks:   []Node{},
+		Sources: []Node{},
+	}
+
+	cards, err := a.cards()
+	if err != nil {
+		return Status{}, err
+	}
+	status.Cards = cards
+
+	data, err := os.ReadFile(filepath.Join(a.root, "pcm"))
+	if errors.Is(err, os.ErrNotExist) {
+		return status, nil
+	}
+	if err != nil {
+		return Status{}, err
+	}
+
+	status.Sinks, status.Sources = parsePCM(data, cards)
+	status.Ready = len(status.Sources) > 0
+
+	return status, nil
+}
+
+// cards liest /proc/asound/cards, ohne die Datei gibt es keine Karten.
+func (a alsa) cards() ([]Card, error) {
+	data, err := os.ReadFile(filepath.Join(a.root, "cards"))
+	if errors.Is(err, os.ErrNotExist) {
+		return []Card{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cards := parseCards(data)
+	for i := range cards {
+		cards[i].Identity = identify(cards[i].Index)
+	}
+	return cards, nil
+}
+
+// identify sucht die udev-Links in /dev/snd/by-id und by-path. Sie zeigen
+// auf das Steuergerät controlCN der Karte.
+func identify(index int) devid.Identity {
+	id := devid.Resolve(devSnd, filepath.Join(devSnd, fmt.Sprintf("controlC%d", index)))
+
+	sysfs := filepath.Join(sysClassSound, fmt.Sprintf("card%d", index), "device")
+	if dev, ok := usb.FromSysfs(sysfs); ok {
+		id.Serial = dev.Serial
+		id.USB = dev.VendorID + ":" + dev.ProductID
+	}
+	return id
+}
+
+// parseCards wertet /proc/asound/cards aus:
+//
+//	0 [PCH            ]: HDA-Intel - HDA Intel PCH
+//	                     HDA Intel PCH at 0xf7f10000 irq 32
+func parseCards(data []byte) []Card {
+	cards := []Card{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		open := strings.Index(line, "[")
+		end := strings.Index(line, "]:")
+		if open < 1 || end < open {
+			continue // Fortsetzungszeile oder "--- no soundcards ---"
+		}
+
+		index, err := strconv.Atoi(strings.TrimSpace(line[:open]))
+		if err != nil {
+			continue
+		}
+
+		card := Card{
+			Index: index,
+			ID:    strings.TrimSpace(line[open+1 : end]),
+		}
+
+		driver, name, _ := strings.Cut(line[end+2:], " - ")
+		card.Driver = strings.TrimSpace(driver)
+		card.Name = strings.TrimSpace(name)
+
+		cards = append(cards, card)
+	}
+
+	return cards
+}
+
+// parsePCM wertet /proc/asound/pcm aus und macht aus jedem PCM-Gerät
+// eine Senke (playback) und/oder Quelle (capture):
+//
+//	00-00: ALC892 Analog : ALC892 Analog : playback 1 : capture 1
+func parsePCM(data []byte, cards []Card) (sinks, sources []Node) {
+	sinks, sources = []Node{}, []Node{}
+
+	cardNames := map[int]string{}
+	for _, c := range cards {
+		cardNames[c.Index] = c.Name
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 3 {
+			continue
+		}
+
+		var card, device int
+		if _, err := fmt.Sscanf(strings.TrimSpace(fields[0]), "%d-%d", &card, &device); err != nil {
+			continue
+		}
+
+		description := strings.TrimSpace(fields[1])
+		if name := cardNames[card]; name != "" {
+			description = name + ": " + description
+		}
+
+		node := Node{
+			ID:          card*100 + device,
+			Name:        fmt.Sprintf("hw:%d,%d", card, device),
+			Description: description,
+		}
+
+		for _, f := range fields[3:] {
+			f = strings.TrimSpace(f)
+			switch {
+			case strings.HasPrefix(f, "playback"):
+				sinks = append(sinks, node)
+			case strings.HasPrefix(f, "capture"):
+				sources = append(sources, node)
+			}
+		}
+	}
+
+	return sinks, sources
+}
@@ -1,13 +1,129 @@
 package audio
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
 // Status beschreibt den Audio-Zustand des Systems.
 type Status struct {
 	Backend string
-	Ready   bool
+	// Ready heißt bei allen Backends: es läuft und hat mindestens eine
+	// Quelle. Fehlt das Werkzeug zum Auflisten (pw-dump, pactl,
+	// jack_lsp), reicht, dass der Server läuft.
+	Ready bool
+
+	Sinks         []Node
+	Sources       []Node
+	DefaultSink   string
+	DefaultSource string
+	Cards         []Card
+}
+
+// Backend ist ein Audio-System, das der Agent erkennen und abfragen kann.
+type Backend interface {
+	Name() string
+	// Detect meldet, ob das Backend auf diesem System gerade aktiv ist.
+	Detect() bool
+	// Probe liest den Zustand des Backends aus. Ein Fehler heißt, dass
+	// das Backend läuft, sich aber nicht abfragen ließ.
+	Probe() (Status, error)
+}
+
+// Backends in der Reihenfolge, in der sie automatisch erkannt werden.
+// PipeWire zuerst, weil es auch die PulseAudio- und JACK-Sockets bereitstellt,
+// JACK vor PulseAudio, weil ein PulseAudio neben JACK meist nur dessen
+// Client ist. ALSA ist der Fallback, wenn kein Soundserver läuft.
+var Backends = []Backend{
+	pipeWire{},
+	jack{},
+	pulseAudio{},
+	alsaBackend,
+}
+
+// Auto wählt das Backend automatisch.
+const Auto = "auto"
+
+// Probe ermittelt den Audio-Status über das angegebene Backend.
+// Bei "auto" (oder leer) wird das erste aktive Backend genommen.
+func Probe(backend string) (Status, error) {
+	status, err := probeBackend(backend)
+	if err != nil {
+		return Status{}, err
+	}
+
+	// Die Karten liest nur das ALSA-Backend selbst, für die stabilen
+	// Namen werden sie aber auch unter einem Soundserver gebraucht
+	if status.Cards == nil {
+		if status.Cards, err = alsaBackend.cards(); err != nil {
+			return Status{}, err
+		}
+	}
+	return status, nil
+}
+
+var alsaBackend = alsa{root: "/proc/asound"}
+
+func probeBackend(backend string) (Status, error) {
+	if backend != "" && backend != Auto {
+		for _, b := range Backends {
+			if b.Name() == backend {
+				return b.Probe()
+			}
+		}
+		return Status{}, fmt.Errorf("unknown audio backend %q", backend)
+	}
+
+	for _, b := range Backends {
+		if b.Detect() {
+			return b.Probe()
+		}
+	}
+
+	return Status{Backend: "none"}, nil
 }
 
-// Probe ermittelt den aktuellen Audio-Status.
-// Aktuell: PipeWire (Stub, aber realistisch).
-func Probe() Status {
-	return probePipeWire()
+// toolTimeout begrenzt, wie lange wir auf pw-dump, pactl und jack_lsp warten.
+const toolTimeout = 3 * time.Second
+
+// errNoTool heißt, dass das Werkzeug zum Auflisten nicht installiert ist.
+var errNoTool = errors.New("not installed")
+
+// runTool führt ein Abfragewerkzeug eines Soundservers aus.
+func runTool(name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), toolTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, fmt.Errorf("%s: %w", name, errNoTool)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return out, nil
+}
+
+// fromInventory füllt den Status eines Soundservers aus seinem Inventar.
+// Ohne Werkzeug zum Auflisten bleibt es beim laufenden Server.
+func fromInventory(status Status, inv Inventory, err error) (Status, error) {
+	if errors.Is(err, errNoTool) {
+		status.Ready = true
+		return status, nil
+	}
+	if err != nil {
+		return Status{}, err
+	}
+
+	status.Sinks = inv.Sinks
+	status.Sources = inv.Sources
+	status.DefaultSink = inv.DefaultSink
+	status.DefaultSource = inv.DefaultSource
+
+	// Ohne Eingang (Mikrofon, Capture-Karte) gibt es nichts aufzunehmen
+	status.Ready = len(inv.Sources) > 0
+	return status, nil
 }
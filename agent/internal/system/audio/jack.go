@@ -0,0 +1,87 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+type jack struct{}
+
+func (jack) Name() string {
+	return "jack"
+}
+
+// jackSockets sind die Server-Sockets von JACK2 und JACK1 für den
+// Standardserver "default", egal unter welchem Benutzer er läuft.
+var jackSockets = []string{
+	"/dev/shm/jack_default_*_0",
+	"/dev/shm/jack-*/default/jack_0",
+}
+
+func (jack) Detect() bool {
+	for _, pattern := range jackSockets {
+		if matches, _ := filepath.Glob(pattern); len(matches) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Probe listet die physischen Ports über jack_lsp. Ohne jack_lsp bleibt
+// es wie bei PipeWire beim Socket-Check.
+func (j jack) Probe() (Status, error) {
+	status := Status{Backend: j.Name()}
+	if !j.Detect() {
+		return status, nil
+	}
+
+	out, err := runTool("jack_lsp", "-p")
+	if err != nil {
+		return fromInventory(status, Inventory{}, err)
+	}
+	sinks, sources := parseJackPorts(out)
+	return fromInventory(status, Inventory{Sinks: sinks, Sources: sources}, nil)
+}
+
+// parseJackPorts wertet "jack_lsp -p" aus. Physische Ausgänge des Servers
+// (output) sind Quellen wie das Mikrofon, physische Eingänge (input) die
+// Lautsprecher; Ports von Programmen zählen nicht:
+//
+//	system:capture_1
+//		properties: output,physical,terminal,
+func parseJackPorts(data []byte) (sinks, sources []Node) {
+	sinks, sources = []Node{}, []Node{}
+
+	var port string
+	id := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		props, ok := strings.CutPrefix(strings.TrimSpace(line), "properties:")
+		if !ok {
+			port = strings.TrimSpace(line)
+			continue
+		}
+		if port == "" {
+			continue
+		}
+
+		flags := strings.Split(strings.TrimSpace(props), ",")
+		if !slices.Contains(flags, "physical") {
+			continue
+		}
+		node := Node{ID: id, Name: port}
+		id++
+		switch {
+		case slices.Contains(flags, "output"):
+			sources = append(sources, node)
+		case slices.Contains(flags, "input"):
+			sinks = append(sinks, node)
+		}
+	}
+
+	return sinks, sources
+}
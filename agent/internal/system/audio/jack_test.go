@@ -0,0 +1,60 @@
+package audio
+
+import "testing"
+
+func TestParseJackPorts(t *testing.T) {
+	data := []byte("system:capture_1\n" +
+		"\tproperties: output,physical,terminal,\n" +
+		"system:capture_2\n" +
+		"\tproperties: output,physical,terminal,\n" +
+		"system:playback_1\n" +
+		"\tproperties: input,physical,terminal,\n" +
+		"obs:in_1\n" +
+		"\tproperties: input,\n")
+
+	sinks, sources := parseJackPorts(data)
+
+	tests := []struct {
+		name  string
+		nodes []Node
+		want  []string
+	}{
+		{"sinks", sinks, []string{"system:playback_1"}},
+		{"sources", sources, []string{"system:capture_1", "system:capture_2"}},
+	}
+	for _, tt := range tests {
+		if len(tt.nodes) != len(tt.want) {
+			t.Errorf("%s = %+v, want %v", tt.name, tt.nodes, tt.want)
+			continue
+		}
+		for i, name := range tt.want {
+			if tt.nodes[i].Name != name {
+				t.Errorf("%s[%d] = %q, want %q", tt.name, i, tt.nodes[i].Name, name)
+			}
+		}
+	}
+}
+
+func TestFromInventoryReady(t *testing.T) {
+	source := []Node{{Name: "mic"}}
+
+	tests := []struct {
+		name string
+		inv  Inventory
+		err  error
+		want bool
+	}{
+		{"with source", Inventory{Sources: source}, nil, true},
+		{"without source", Inventory{Sinks: source}, nil, false},
+		{"tool missing", Inventory{}, errNoTool, true},
+	}
+	for _, tt := range tests {
+		status, err := fromInventory(Status{Backend: "jack"}, tt.inv, tt.err)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if status.Ready != tt.want {
+			t.Errorf("%s: Ready = %v, want %v", tt.name, status.Ready, tt.want)
+		}
+	}
+}
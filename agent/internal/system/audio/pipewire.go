@@ -0,0 +1,40 @@
+package audio
+
+import (
+	"os"
+	"path/filepath"
+)
+
+type pipeWire struct{}
+
+func (pipeWire) Name() string {
+	return "pipewire"
+}
+
+// Detect prüft das PipeWire-Socket im XDG_RUNTIME_DIR.
+func (pipeWire) Detect() bool {
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		return false
+	}
+
+	_, err := os.Stat(filepath.Join(runtimeDir, "pipewire-0"))
+	return err == nil
+}
+
+// Probe prüft, ob PipeWire aktiv ist, und liest die Registry aus.
+// Ohne pw-dump bleibt es beim Socket-Check; scheitert ein vorhandenes
+// pw-dump, ist das ein Fehler.
+func (p pipeWire) Probe() (Status, error) {
+	status := Status{
+		Backend: p.Name(),
+		Ready:   false,
+	}
+
+	if !p.Detect() {
+		return status, nil
+	}
+
+	inv, err := readPipeWireInventory()
+	return fromInventory(status, inv, err)
+}
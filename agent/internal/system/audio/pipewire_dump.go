@@ -0,0 +1,195 @@
+package audio
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Node ist eine Audio-Senke (Ausgang) oder -Quelle (Eingang, z.B. Mikrofon).
+type Node struct {
+	ID          int     `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Channels    int     `json:"channels,omitempty"`
+	SampleRate  int     `json:"sample_rate,omitempty"`
+	Muted       bool    `json:"muted"`
+	Volume      float64 `json:"volume"`
+}
+
+// Inventory sind die Senken und Quellen eines Soundservers, bei PipeWire
+// der Audio-Teil der Registry.
+type Inventory struct {
+	Sinks         []Node
+	Sources       []Node
+	DefaultSink   string
+	DefaultSource string
+}
+
+// readPipeWireInventory liest die Registry über pw-dump aus.
+func readPipeWireInventory() (Inventory, error) {
+	out, err := runTool("pw-dump", "--no-colors")
+	if err != nil {
+		return Inventory{}, err
+	}
+
+	return ParseDump(out)
+}
+
+// pwObject ist ein Eintrag aus der JSON-Ausgabe von pw-dump.
+// Nur die Felder, die wir brauchen.
+type pwObject struct {
+	ID   int    `json:"id"`
+	Type string `json:"type"`
+	Info struct {
+		Props  map[string]any `json:"props"`
+		Params struct {
+			Props  []pwProps  `json:"Props"`
+			Format []pwFormat `json:"Format"`
+		} `json:"params"`
+	} `json:"info"`
+
+	// Nur bei Metadata-Objekten
+	Props    map[string]any `json:"props"`
+	Metadata []struct {
+		Subject int             `json:"subject"`
+		Key     string          `json:"key"`
+		Value   json.RawMessage `json:"value"`
+	} `json:"metadata"`
+}
+
+type pwProps struct {
+	Mute           *bool     `json:"mute"`
+	ChannelVolumes []float64 `json:"channelVolumes"`
+}
+
+type pwFormat struct {
+	Rate     int `json:"rate"`
+	Channels int `json:"channels"`
+}
+
+// ParseDump wertet die JSON-Ausgabe von pw-dump aus.
+func ParseDump(data []byte) (Inventory, error) {
+	var objects []pwObject
+	if err := json.Unmarshal(data, &objects); err != nil {
+		return Inventory{}, fmt.Errorf("parsing pw-dump output: %w", err)
+	}
+
+	inv := Inventory{
+		Sinks:   []Node{},
+		Sources: []Node{},
+	}
+
+	for _, obj := range objects {
+		switch obj.Type {
+		case "PipeWire:Interface:Node":
+			// Duplex-Knoten (z.B. Pro-Audio-Profile) können beides
+			class := propString(obj.Info.Props, "media.class")
+			if class == "Audio/Sink" || class == "Audio/Duplex" {
+				inv.Sinks = append(inv.Sinks, parseNode(obj))
+			}
+			if strings.HasPrefix(class, "Audio/Source") || class == "Audio/Duplex" {
+				inv.Sources = append(inv.Sources, parseNode(obj))
+			}
+
+		case "PipeWire:Interface:Metadata":
+			if propString(obj.Props, "metadata.name") != "default" {
+				continue
+			}
+			for _, m := range obj.Metadata {
+				switch m.Key {
+				case "default.audio.sink":
+					inv.DefaultSink = metadataName(m.Value)
+				case "default.audio.source":
+					inv.DefaultSource = metadataName(m.Value)
+				}
+			}
+		}
+	}
+
+	sort.Slice(inv.Sinks, func(i, j int) bool { return inv.Sinks[i].Name < inv.Sinks[j].Name })
+	sort.Slice(inv.Sources, func(i, j int) bool { return inv.Sources[i].Name < inv.Sources[j].Name })
+
+	return inv, nil
+}
+
+func parseNode(obj pwObject) Node {
+	props := obj.Info.Props
+
+	node := Node{
+		ID:          obj.ID,
+		Name:        propString(props, "node.name"),
+		Description: propString(props, "node.description"),
+		Channels:    propInt(props, "audio.channels"),
+		SampleRate:  propInt(props, "audio.rate"),
+	}
+
+	// Das ausgehandelte Format ist genauer als die Props
+	if len(obj.Info.Params.Format) > 0 {
+		f := obj.Info.Params.Format[0]
+		if f.Channels > 0 {
+			node.Channels = f.Channels
+		}
+		if f.Rate > 0 {
+			node.SampleRate = f.Rate
+		}
+	}
+
+	if len(obj.Info.Params.Props) > 0 {
+		p := obj.Info.Params.Props[0]
+		if p.Mute != nil {
+			node.Muted = *p.Mute
+		}
+		if node.Channels == 0 {
+			node.Channels = len(p.ChannelVolumes)
+		}
+		node.Volume = volume(p.ChannelVolumes)
+	}
+
+	return node
+}
+
+// volume rechnet die linearen Kanal-Lautstärken in den Wert um, den auch
+// pavucontrol und wpctl anzeigen (kubische Skala, 1.0 = 100%).
+func volume(channels []float64) float64 {
+	if len(channels) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, v := range channels {
+		sum += math.Cbrt(v)
+	}
+	return math.Round(sum/float64(len(channels))*100) / 100
+}
+
+// metadataName liest {"name": "..."} aus einem Metadata-Wert.
+func metadataName(raw json.RawMessage) string {
+	var v struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return ""
+	}
+	return v.Name
+}
+
+func propString(props map[string]any, key string) string {
+	s, _ := props[key].(string)
+	return s
+}
+
+// propInt liest Zahlen, die pw-dump je nach Quelle als Zahl oder String liefert.
+func propInt(props map[string]any, key string) int {
+	switch v := props[key].(type) {
+	case float64:
+		return int(v)
+	case string:
+		var n int
+		fmt.Sscanf(v, "%d", &n)
+		return n
+	}
+	return 0
+}
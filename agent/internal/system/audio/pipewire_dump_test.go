@@ -0,0 +1,73 @@
+package audio
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseDump(t *testing.T) {
+	data, err := os.ReadFile("testdata/pw-dump.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inv, err := ParseDump(data)
+	if err != nil {
+		t.Fatalf("ParseDump() error = %v", err)
+	}
+
+	const (
+		duplex = "alsa_card.usb-Focusrite_Scarlett_2i2_USB-00.pro-audio"
+		mic    = "alsa_input.usb-Elgato_Systems_Elgato_Wave_3_BS22K1A02345-00.mono-fallback"
+		output = "alsa_output.pci-0000_00_1f.3.analog-stereo"
+	)
+
+	tests := []struct {
+		name  string
+		nodes []Node
+		want  []Node
+	}{
+		{
+			name:  "sinks",
+			nodes: inv.Sinks,
+			want: []Node{
+				{ID: 61, Name: duplex, Description: "Scarlett 2i2 Pro", Channels: 2, SampleRate: 48000},
+				{ID: 56, Name: output, Description: "Built-in Audio Analog Stereo", Channels: 2, SampleRate: 44100, Muted: true, Volume: 0.5},
+			},
+		},
+		{
+			name:  "sources",
+			nodes: inv.Sources,
+			want: []Node{
+				{ID: 61, Name: duplex, Description: "Scarlett 2i2 Pro", Channels: 2, SampleRate: 48000},
+				{ID: 55, Name: mic, Description: "Wave:3 Mono", Channels: 1, SampleRate: 48000, Volume: 0.8},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.nodes) != len(tt.want) {
+				t.Fatalf("got %d nodes, want %d: %+v", len(tt.nodes), len(tt.want), tt.nodes)
+			}
+			for i := range tt.want {
+				if tt.nodes[i] != tt.want[i] {
+					t.Errorf("node %d = %+v, want %+v", i, tt.nodes[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	if inv.DefaultSink != output {
+		t.Errorf("DefaultSink = %q, want %q", inv.DefaultSink, output)
+	}
+	if inv.DefaultSource != mic {
+		t.Errorf("DefaultSource = %q, want %q", inv.DefaultSource, mic)
+	}
+}
+
+func TestParseDumpInvalid(t *testing.T) {
+	if _, err := ParseDump([]byte("pw-dump: can't connect")); err == nil {
+		t.Error("ParseDump() error = nil, want a parse error")
+	}
+}
@@ -0,0 +1,134 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type pulseAudio struct{}
+
+func (pulseAudio) Name() string {
+	return "pulseaudio"
+}
+
+// socket liefert den Pfad des nativen PulseAudio-Sockets.
+// PULSE_SERVER hat Vorrang, sonst XDG_RUNTIME_DIR/pulse/native.
+func (pulseAudio) socket() string {
+	if server := os.Getenv("PULSE_SERVER"); strings.HasPrefix(server, "unix:") {
+		return strings.TrimPrefix(server, "unix:")
+	}
+
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		return ""
+	}
+	return filepath.Join(runtimeDir, "pulse", "native")
+}
+
+func (p pulseAudio) Detect() bool {
+	socket := p.socket()
+	if socket == "" {
+		return false
+	}
+
+	info, err := os.Stat(socket)
+	return err == nil && info.Mode()&os.ModeSocket != 0
+}
+
+// Probe listet Senken und Quellen über pactl. Ohne pactl bleibt es wie
+// bei PipeWire beim Socket-Check.
+func (p pulseAudio) Probe() (Status, error) {
+	status := Status{Backend: p.Name()}
+	if !p.Detect() {
+		return status, nil
+	}
+
+	inv, err := readPulseInventory()
+	return fromInventory(status, inv, err)
+}
+
+func readPulseInventory() (Inventory, error) {
+	sinks, err := runTool("pactl", "list", "short", "sinks")
+	if err != nil {
+		return Inventory{}, err
+	}
+	sources, err := runTool("pactl", "list", "short", "sources")
+	if err != nil {
+		return Inventory{}, err
+	}
+	info, err := runTool("pactl", "info")
+	if err != nil {
+		return Inventory{}, err
+	}
+
+	inv := Inventory{
+		Sinks:   parsePactlShort(sinks),
+		Sources: []Node{},
+	}
+	// Die Monitore der Senken sind keine Eingänge
+	for _, n := range parsePactlShort(sources) {
+		if !strings.HasSuffix(n.Name, ".monitor") {
+			inv.Sources = append(inv.Sources, n)
+		}
+	}
+	inv.DefaultSink, inv.DefaultSource = parsePactlInfo(info)
+	return inv, nil
+}
+
+// parsePactlShort wertet "pactl list short sinks" bzw. "sources" aus:
+//
+//	47	alsa_output.pci-0000_00_1f.3.analog-stereo	module-alsa-card.c	s16le 2ch 44100Hz	SUSPENDED
+func parsePactlShort(data []byte) []Node {
+	nodes := []Node{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 2 {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimSpace(fields[0]))
+		if err != nil {
+			continue
+		}
+
+		node := Node{ID: id, Name: strings.TrimSpace(fields[1])}
+		if len(fields) >= 4 {
+			for _, spec := range strings.Fields(fields[3]) {
+				switch {
+				case strings.HasSuffix(spec, "ch"):
+					node.Channels, _ = strconv.Atoi(strings.TrimSuffix(spec, "ch"))
+				case strings.HasSuffix(spec, "Hz"):
+					node.SampleRate, _ = strconv.Atoi(strings.TrimSuffix(spec, "Hz"))
+				}
+			}
+		}
+		nodes = append(nodes, node)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes
+}
+
+// parsePactlInfo liest Standard-Senke und -Quelle aus "pactl info".
+func parsePactlInfo(data []byte) (sink, source string) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "Default Sink":
+			sink = strings.TrimSpace(value)
+		case "Default Source":
+			source = strings.TrimSpace(value)
+		}
+	}
+	return sink, source
+}
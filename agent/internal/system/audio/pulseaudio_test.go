@@ -0,0 +1,39 @@
+package audio
+
+import "testing"
+
+func TestParsePactlShort(t *testing.T) {
+	data := []byte("47\talsa_output.pci-0000_00_1f.3.analog-stereo\tmodule-alsa-card.c\ts16le 2ch 44100Hz\tSUSPENDED\n" +
+		"52\talsa_input.usb-Elgato_Wave_3-00.mono-fallback\tmodule-alsa-card.c\ts24le 1ch 48000Hz\tRUNNING\n" +
+		"not a node\n")
+
+	// Sorted by name like the PipeWire nodes
+	nodes := parsePactlShort(data)
+	want := []Node{
+		{ID: 52, Name: "alsa_input.usb-Elgato_Wave_3-00.mono-fallback", Channels: 1, SampleRate: 48000},
+		{ID: 47, Name: "alsa_output.pci-0000_00_1f.3.analog-stereo", Channels: 2, SampleRate: 44100},
+	}
+	if len(nodes) != len(want) {
+		t.Fatalf("got %d nodes, want %d: %+v", len(nodes), len(want), nodes)
+	}
+	for i := range want {
+		if nodes[i] != want[i] {
+			t.Errorf("node %d = %+v, want %+v", i, nodes[i], want[i])
+		}
+	}
+}
+
+func TestParsePactlInfo(t *testing.T) {
+	data := []byte("Server String: /run/user/1000/pulse/native\n" +
+		"Server Name: pulseaudio\n" +
+		"Default Sink: alsa_output.pci-0000_00_1f.3.analog-stereo\n" +
+		"Default Source: alsa_input.usb-Elgato_Wave_3-00.mono-fallback\n")
+
+	sink, source := parsePactlInfo(data)
+	if sink != "alsa_output.pci-0000_00_1f.3.analog-stereo" {
+		t.Errorf("sink = %q", sink)
+	}
+	if source != "alsa_input.usb-Elgato_Wave_3-00.mono-fallback" {
+		t.Errorf("source = %q", source)
+	}
+}
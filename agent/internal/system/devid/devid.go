@@ -0,0 +1,77 @@
+// Package devid resolves device nodes like /dev/video0, whose numbers
+// change between boots and replugs, to the stable names udev links to
+// them.
+package devid
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+)
+
+// Identity holds the stable names of a device node.
+type Identity struct {
+	// ID is the name to remember the device by: the first by-id link,
+	// or the first by-path link for devices without one (usually
+	// internal or PCIe devices). Empty if udev created neither.
+	ID     string   `json:"id,omitempty"`
+	ByID   []string `json:"by_id"`
+	ByPath []string `json:"by_path"`
+	// Serial is the USB serial number from sysfs, if the device has one
+	Serial string `json:"serial,omitempty"`
+	// USB is the vendor and product ID of USB devices, e.g. "0fd9:0066"
+	USB string `json:"usb,omitempty"`
+}
+
+// Resolve looks up the links to node in the by-id and by-path
+// directories below dir, e.g. /dev/v4l or /dev/snd.
+func Resolve(dir, node string) Identity {
+	id := Identity{
+		ByID:   Links(filepath.Join(dir, "by-id"), node),
+		ByPath: Links(filepath.Join(dir, "by-path"), node),
+	}
+
+	switch {
+	case len(id.ByID) > 0:
+		id.ID = id.ByID[0]
+	case len(id.ByPath) > 0:
+		id.ID = id.ByPath[0]
+	}
+	return id
+}
+
+// Links returns the names of the symlinks in dir that point to node,
+// sorted.
+func Links(dir, node string) []string {
+	links := []string{}
+
+	target, err := filepath.EvalSymlinks(node)
+	if err != nil {
+		return links
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return links
+	}
+
+	for _, e := range entries {
+		resolved, err := filepath.EvalSymlinks(filepath.Join(dir, e.Name()))
+		if err == nil && resolved == target {
+			links = append(links, e.Name())
+		}
+	}
+
+	sort.Strings(links)
+	return links
+}
+
+// Matches reports whether value names the device: its ID or any of its
+// links.
+func (id Identity) Matches(value string) bool {
+	if value == "" {
+		return false
+	}
+	return value == id.ID || slices.Contains(id.ByID, value) || slices.Contains(id.ByPath, value)
+}
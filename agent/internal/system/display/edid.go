@@ -0,0 +1,83 @@
+package display
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+)
+
+var edidHeader = []byte{0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x00}
+
+// Descriptor tags in the 18-byte display descriptors
+const (
+	descSerial = 0xFF
+	descName   = 0xFC
+)
+
+// parseEDID fills in what the EDID base block says about the monitor.
+// Extension blocks (CTA-861 etc.) aren't needed for that.
+func parseEDID(data []byte, m *Monitor) {
+	if len(data) < 128 || !bytes.Equal(data[:8], edidHeader) {
+		return
+	}
+
+	// Three letters of five bits each, 1 = 'A'
+	id := binary.BigEndian.Uint16(data[8:10])
+	m.Manufacturer = string([]byte{
+		byte(id>>10&0x1F) + '@',
+		byte(id>>5&0x1F) + '@',
+		byte(id&0x1F) + '@',
+	})
+	m.ProductCode = binary.LittleEndian.Uint16(data[10:12])
+	if serial := binary.LittleEndian.Uint32(data[12:16]); serial != 0 {
+		m.Serial = fmt.Sprint(serial)
+	}
+	m.WidthCM, m.HeightCM = int(data[21]), int(data[22])
+
+	for off := 54; off+18 <= 126; off += 18 {
+		desc := data[off : off+18]
+
+		// A pixel clock means a detailed timing; the first one is the
+		// preferred mode
+		if desc[0] != 0 || desc[1] != 0 {
+			if off == 54 {
+				m.PreferredMode = preferredMode(desc)
+			}
+			continue
+		}
+
+		text := descriptorText(desc[5:])
+		switch desc[3] {
+		case descName:
+			m.Name = text
+		case descSerial:
+			// The text serial is the useful one, the numeric is often 0 or 1
+			m.Serial = text
+		}
+	}
+}
+
+func preferredMode(desc []byte) string {
+	clock := float64(binary.LittleEndian.Uint16(desc[0:2])) * 10000
+	hActive := int(desc[2]) | int(desc[4]&0xF0)<<4
+	hBlank := int(desc[3]) | int(desc[4]&0x0F)<<8
+	vActive := int(desc[5]) | int(desc[7]&0xF0)<<4
+	vBlank := int(desc[6]) | int(desc[7]&0x0F)<<8
+
+	total := float64((hActive + hBlank) * (vActive + vBlank))
+	if total == 0 {
+		return fmt.Sprintf("%dx%d", hActive, vActive)
+	}
+	return fmt.Sprintf("%dx%d@%g", hActive, vActive, math.Round(clock/total*100)/100)
+}
+
+// descriptorText reads a descriptor string, ended by a newline and padded
+// with spaces.
+func descriptorText(b []byte) string {
+	if i := bytes.IndexByte(b, 0x0A); i >= 0 {
+		b = b[:i]
+	}
+	return strings.TrimSpace(string(b))
+}
@@ -0,0 +1,78 @@
+package display
+
+import "testing"
+
+// testEDID builds an EDID base block for a 1920x1080@60 "DELL U2720Q".
+// Descriptors beyond the preferred timing are set by the callers.
+func testEDID() []byte {
+	data := make([]byte, 128)
+	copy(data, edidHeader)
+	data[8], data[9] = 0x10, 0xac   // "DEL"
+	data[10], data[11] = 0xe9, 0xa0 // product 0xa0e9
+	data[12], data[13], data[14] = 0x45, 0x23, 0x01
+	data[21], data[22] = 60, 34
+
+	// 148.5 MHz, 1920+280 x 1080+45
+	copy(data[54:], []byte{0x02, 0x3a, 0x80, 0x18, 0x71, 0x38, 0x2d, 0x40})
+	return data
+}
+
+func descriptor(data []byte, off int, tag byte, text string) {
+	desc := data[off : off+18]
+	desc[3] = tag
+	body := desc[5:]
+	for i := range body {
+		body[i] = ' '
+	}
+	n := copy(body, text)
+	if n < len(body) {
+		body[n] = 0x0a
+	}
+}
+
+func TestParseEDID(t *testing.T) {
+	full := testEDID()
+	descriptor(full, 72, descName, "DELL U2720Q")
+	descriptor(full, 90, descSerial, "ABC123")
+
+	notEDID := testEDID()
+	notEDID[0] = 0x01
+
+	tests := []struct {
+		name string
+		data []byte
+		want Monitor
+	}{
+		{
+			name: "name and text serial",
+			data: full,
+			want: Monitor{
+				Name: "DELL U2720Q", Manufacturer: "DEL", ProductCode: 0xa0e9, Serial: "ABC123",
+				WidthCM: 60, HeightCM: 34, PreferredMode: "1920x1080@60",
+			},
+		},
+		{
+			name: "numeric serial only",
+			data: testEDID(),
+			want: Monitor{
+				Manufacturer: "DEL", ProductCode: 0xa0e9, Serial: "74565",
+				WidthCM: 60, HeightCM: 34, PreferredMode: "1920x1080@60",
+			},
+		},
+		{name: "truncated", data: full[:100]},
+		{name: "bad header", data: notEDID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Monitor
+			parseEDID(tt.data, &m)
+
+			got := [...]any{m.Name, m.Manufacturer, m.ProductCode, m.Serial, m.WidthCM, m.HeightCM, m.PreferredMode}
+			want := [...]any{tt.want.Name, tt.want.Manufacturer, tt.want.ProductCode, tt.want.Serial, tt.want.WidthCM, tt.want.HeightCM, tt.want.PreferredMode}
+			if got != want {
+				t.Errorf("parseEDID() = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+//go:build linux
+
+package display
+
+import (
+	"os"
+	"syscall"
+)
+
+func owner(info os.FileInfo) int {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return int(st.Uid)
+	}
+	return -1
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package display
+
+import "os"
+
+// owner is only implemented on Linux.
+func owner(info os.FileInfo) int {
+	return -1
+}
@@ -0,0 +1,239 @@
+package display
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Prober reads sessions, sockets and connectors below Root, so tests can
+// point it at a fake tree.
+type Prober struct {
+	Root string
+}
+
+func NewProber(root string) *Prober {
+	return &Prober{Root: root}
+}
+
+func Probe() Status {
+	return NewProber("/").Probe()
+}
+
+func (p *Prober) Probe() Status {
+	status := Status{
+		Sessions: p.sessions(),
+		Sockets:  p.sockets(),
+		Monitors: p.monitors(),
+	}
+
+	graphical := map[int]bool{}
+	for _, s := range status.Sessions {
+		if s.Graphical() {
+			status.Available = true
+			graphical[s.UID] = true
+		}
+	}
+	// Sockets cover compositors started without logind, e.g. from a kiosk
+	// unit. With logind sessions around only the sockets of graphical user
+	// sessions count, the login screen has a socket of its own.
+	for _, s := range status.Sockets {
+		if len(status.Sessions) == 0 || graphical[s.UID] {
+			status.Available = true
+		}
+	}
+
+	return status
+}
+
+func (p *Prober) path(path string) string {
+	return filepath.Join(p.Root, path)
+}
+
+// sessions reads logind's state files in /run/systemd/sessions. They are
+// KEY=VALUE lines, the same data loginctl shows.
+func (p *Prober) sessions() []Session {
+	dir := p.path("/run/systemd/sessions")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return []Session{}
+	}
+
+	sessions := []Session{}
+	for _, e := range entries {
+		// Skip the *.ref FIFOs
+		if e.IsDir() || strings.Contains(e.Name(), ".") {
+			continue
+		}
+
+		values := readEnvFile(filepath.Join(dir, e.Name()))
+		if values == nil {
+			continue
+		}
+
+		uid, _ := strconv.Atoi(values["UID"])
+		sessions = append(sessions, Session{
+			ID:      e.Name(),
+			User:    values["USER"],
+			UID:     uid,
+			Type:    values["TYPE"],
+			Class:   values["CLASS"],
+			State:   values["STATE"],
+			Active:  values["ACTIVE"] == "1",
+			Seat:    values["SEAT"],
+			Display: values["DISPLAY"],
+			Desktop: values["DESKTOP"],
+			Remote:  values["REMOTE"] == "1",
+		})
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ID < sessions[j].ID })
+	return sessions
+}
+
+func readEnvFile(path string) map[string]string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	values := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if ok && !strings.HasPrefix(key, "#") {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// sockets finds Wayland compositors in the users' runtime directories
+// and X servers in /tmp/.X11-unix. Stale sockets a crashed server left
+// behind are skipped.
+func (p *Prober) sockets() []Socket {
+	sockets := []Socket{}
+
+	userDirs, _ := filepath.Glob(p.path("/run/user/*"))
+	for _, dir := range userDirs {
+		uid, err := strconv.Atoi(filepath.Base(dir))
+		if err != nil {
+			continue
+		}
+
+		matches, _ := filepath.Glob(filepath.Join(dir, "wayland-*"))
+		for _, match := range matches {
+			if strings.HasSuffix(match, ".lock") || !isSocket(match) || !listening(match) {
+				continue
+			}
+			sockets = append(sockets, Socket{
+				Type:    "wayland",
+				Path:    strings.TrimPrefix(match, strings.TrimSuffix(p.Root, "/")),
+				Display: filepath.Base(match),
+				UID:     uid,
+			})
+		}
+	}
+
+	matches, _ := filepath.Glob(p.path("/tmp/.X11-unix/X*"))
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil || info.Mode()&os.ModeSocket == 0 || !listening(match) {
+			continue
+		}
+		sockets = append(sockets, Socket{
+			Type:    "x11",
+			Path:    strings.TrimPrefix(match, strings.TrimSuffix(p.Root, "/")),
+			Display: ":" + strings.TrimPrefix(filepath.Base(match), "X"),
+			UID:     owner(info),
+		})
+	}
+
+	return sockets
+}
+
+func isSocket(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode()&os.ModeSocket != 0
+}
+
+// listening reports whether a server accepts connections on the socket.
+// Only a refused connection marks it stale; a socket the agent may not
+// connect to still counts.
+func listening(path string) bool {
+	conn, err := net.DialTimeout("unix", path, 200*time.Millisecond)
+	if err != nil {
+		return !errors.Is(err, syscall.ECONNREFUSED)
+	}
+	_ = conn.Close()
+	return true
+}
+
+// monitors lists the connected connectors of all DRM cards.
+func (p *Prober) monitors() []Monitor {
+	dirs, _ := filepath.Glob(p.path("/sys/class/drm/card*-*"))
+	monitors := []Monitor{}
+
+	for _, dir := range dirs {
+		card, connector, ok := strings.Cut(filepath.Base(dir), "-")
+		if !ok {
+			continue
+		}
+
+		if readTrimmed(filepath.Join(dir, "status")) != "connected" {
+			continue
+		}
+
+		monitor := Monitor{
+			Card:      card,
+			Connector: connector,
+			Enabled:   readTrimmed(filepath.Join(dir, "enabled")) == "enabled",
+			Modes:     readModes(filepath.Join(dir, "modes")),
+		}
+
+		if data, err := os.ReadFile(filepath.Join(dir, "edid")); err == nil {
+			parseEDID(data, &monitor)
+		}
+
+		monitors = append(monitors, monitor)
+	}
+
+	sort.Slice(monitors, func(i, j int) bool {
+		if monitors[i].Card != monitors[j].Card {
+			return monitors[i].Card < monitors[j].Card
+		}
+		return monitors[i].Connector < monitors[j].Connector
+	})
+	return monitors
+}
+
+// readModes returns the distinct modes; the file repeats a resolution for
+// every refresh rate.
+func readModes(path string) []string {
+	modes := []string{}
+	seen := map[string]bool{}
+	for _, line := range strings.Split(readTrimmed(path), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !seen[line] {
+			seen[line] = true
+			modes = append(modes, line)
+		}
+	}
+	return modes
+}
+
+func readTrimmed(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,102 @@
+package display
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"kit.workmate/live-agent/internal/testutil"
+)
+
+// session returns a logind session file as in /run/systemd/sessions.
+func session(uid, typ, class string) string {
+	return "UID=" + uid + "\nUSER=u" + uid + "\nACTIVE=1\nSTATE=active\nTYPE=" + typ + "\nCLASS=" + class + "\nSEAT=seat0\n"
+}
+
+func TestProbeAvailable(t *testing.T) {
+	tests := []struct {
+		name     string
+		sessions map[string]string
+		// sockets maps socket paths to whether a server listens on them
+		sockets map[string]bool
+		want    bool
+	}{
+		{
+			name:     "user session",
+			sessions: map[string]string{"2": session("1000", "wayland", "user")},
+			want:     true,
+		},
+		{
+			name:     "greeter socket only",
+			sessions: map[string]string{"c1": session("120", "wayland", "greeter")},
+			sockets:  map[string]bool{"/run/user/120/wayland-0": true},
+			want:     false,
+		},
+		{
+			name: "socket of a text session's user",
+			sessions: map[string]string{
+				"c1": session("120", "wayland", "greeter"),
+				"3":  session("1000", "tty", "user"),
+			},
+			sockets: map[string]bool{"/run/user/1000/wayland-1": true},
+			want:    false,
+		},
+		{
+			name:    "kiosk without logind",
+			sockets: map[string]bool{"/run/user/1000/wayland-0": true},
+			want:    true,
+		},
+		{
+			name:    "stale x11 socket without logind",
+			sockets: map[string]bool{"/tmp/.X11-unix/X0": false},
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]string{}
+			for id, content := range tt.sessions {
+				files[filepath.Join("run/systemd/sessions", id)] = content
+			}
+			root := testutil.FakeTree(t, files, nil)
+
+			live := 0
+			for path, listening := range tt.sockets {
+				listen(t, filepath.Join(root, path), listening)
+				if listening {
+					live++
+				}
+			}
+
+			status := NewProber(root).Probe()
+			if status.Available != tt.want {
+				t.Errorf("Available = %v, want %v", status.Available, tt.want)
+			}
+			if len(status.Sockets) != live {
+				t.Errorf("got %d sockets, want the %d live ones: %+v", len(status.Sockets), live, status.Sockets)
+			}
+		})
+	}
+}
+
+// listen creates a unix socket at path. Without listening the socket
+// file stays behind like that of a crashed server.
+func listen(t *testing.T, path string, listening bool) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !listening {
+		ln.(*net.UnixListener).SetUnlinkOnClose(false)
+		ln.Close()
+		return
+	}
+	t.Cleanup(func() { ln.Close() })
+}
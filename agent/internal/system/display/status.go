@@ -0,0 +1,68 @@
+package display
+
+// Status tells whether a graphical session OBS could render into exists,
+// independent of the agent's own environment, which has no DISPLAY when
+// it runs as a system service.
+type Status struct {
+	// Available is true if a graphical user session exists, or, on
+	// systems without logind sessions, any live display socket
+	Available bool      `json:"available"`
+	Sessions  []Session `json:"sessions"`
+	// Sockets are the Wayland and X11 sockets found, e.g.
+	// /run/user/1000/wayland-0 or /tmp/.X11-unix/X0
+	Sockets  []Socket  `json:"sockets"`
+	Monitors []Monitor `json:"monitors"`
+}
+
+// Session is a logind session.
+type Session struct {
+	ID      string `json:"id"`
+	User    string `json:"user"`
+	UID     int    `json:"uid"`
+	Type    string `json:"type"`  // wayland, x11, mir, tty, unspecified
+	Class   string `json:"class"` // user, greeter, lock-screen, ...
+	State   string `json:"state"` // active, online, closing
+	Active  bool   `json:"active"`
+	Seat    string `json:"seat,omitempty"`
+	Display string `json:"display,omitempty"` // X11 display, e.g. ":0"
+	Desktop string `json:"desktop,omitempty"`
+	Remote  bool   `json:"remote"`
+}
+
+// Graphical reports whether the session is a desktop a user works in,
+// as opposed to a text console or the login screen.
+func (s Session) Graphical() bool {
+	switch s.Type {
+	case "wayland", "x11", "mir":
+		return s.Class == "user" && s.State != "closing"
+	}
+	return false
+}
+
+type Socket struct {
+	Type string `json:"type"` // wayland or x11
+	Path string `json:"path"`
+	// Display is the value for WAYLAND_DISPLAY or DISPLAY
+	Display string `json:"display"`
+	UID     int    `json:"uid"`
+}
+
+// Monitor is a connected DRM connector.
+type Monitor struct {
+	Card      string `json:"card"`
+	Connector string `json:"connector"` // e.g. HDMI-A-1, DP-2, eDP-1
+	Enabled   bool   `json:"enabled"`
+
+	// From the EDID, if the monitor provides one
+	Name         string `json:"name,omitempty"`
+	Manufacturer string `json:"manufacturer,omitempty"`
+	ProductCode  uint16 `json:"product_code,omitempty"`
+	Serial       string `json:"serial,omitempty"`
+	WidthCM      int    `json:"width_cm,omitempty"`
+	HeightCM     int    `json:"height_cm,omitempty"`
+	// PreferredMode is the monitor's native resolution and refresh rate
+	PreferredMode string `json:"preferred_mode,omitempty"`
+
+	// Modes are the resolutions the driver offers, best first
+	Modes []string `json:"modes"`
+}
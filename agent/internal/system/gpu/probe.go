@@ -5,15 +5,43 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+
+	"kit.workmate/live-agent/internal/system/pci"
 )
 
-func Probe() Status {
-	renderNodes, _ := filepath.Glob("/dev/dri/renderD*")
+// Prober reads GPU state below Root, so tests can point it at a fake tree.
+type Prober struct {
+	Root string
+}
+
+func NewProber(root string) *Prober {
+	return &Prober{Root: root}
+}
+
+func Probe() (Status, error) {
+	return NewProber("/").Probe()
+}
+
+// Probe lists the render nodes and their devices. The error is set if
+// there are render nodes but sysfs can't be read.
+func (p *Prober) Probe() (Status, error) {
+	renderNodes, _ := filepath.Glob(p.path("/dev/dri/renderD*"))
 	if len(renderNodes) == 0 {
-		return Status{Present: false}
+		return Status{Present: false}, nil
 	}
 
-	vendors := detectVendors()
+	for i, node := range renderNodes {
+		renderNodes[i] = "/dev/dri/" + filepath.Base(node)
+	}
+
+	vendors, err := p.detectVendors()
+	if err != nil {
+		return Status{}, err
+	}
+	devices, err := p.devices()
+	if err != nil {
+		return Status{}, err
+	}
 
 	sort.Strings(renderNodes)
 	sort.Strings(vendors)
@@ -22,13 +50,20 @@ func Probe() Status {
 		Present:     true,
 		Vendors:     vendors,
 		RenderNodes: renderNodes,
-	}
+		Devices:     devices,
+	}, nil
 }
 
-func detectVendors() []string {
-	entries, err := os.ReadDir("/sys/class/drm")
+func (p *Prober) path(path string) string {
+	return filepath.Join(p.Root, path)
+}
+
+func (p *Prober) detectVendors() ([]string, error) {
+	drmDir := p.path("/sys/class/drm")
+
+	entries, err := readDRM(drmDir)
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
 	seen := map[string]bool{}
@@ -39,7 +74,7 @@ func detectVendors() []string {
 			continue
 		}
 
-		vendorFile := filepath.Join("/sys/class/drm", e.Name(), "device/vendor")
+		vendorFile := filepath.Join(drmDir, e.Name(), "device/vendor")
 		data, err := os.ReadFile(vendorFile)
 		if err != nil {
 			continue
@@ -55,9 +90,11 @@ func detectVendors() []string {
 		vendors = append(vendors, name)
 	}
 
-	return vendors
+	return vendors, nil
 }
 
+// vendorName keeps the short names for the big three, which are used as
+// metric labels and by the portal, and looks everything else up in pci.ids.
 func vendorName(id string) string {
 	switch id {
 	case "0x8086":
@@ -66,7 +103,10 @@ func vendorName(id string) string {
 		return "amd"
 	case "0x10de":
 		return "nvidia"
-	default:
-		return "unknown"
 	}
+
+	if name := pci.Database().Vendor(id); name != "" {
+		return name
+	}
+	return "unknown"
 }
@@ -0,0 +1,166 @@
+package gpu
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"kit.workmate/live-agent/internal/testutil"
+)
+
+func TestProbeTelemetry(t *testing.T) {
+	const (
+		amd   = "/sys/devices/pci0000:00/0000:03:00.0"
+		intel = "/sys/devices/pci0000:00/0000:00:02.0"
+	)
+
+	root := testutil.FakeTree(t, map[string]string{
+		"/dev/dri/renderD128": "",
+		"/dev/dri/renderD129": "",
+
+		amd + "/vendor":                      "0x1002\n",
+		amd + "/device":                      "0x73bf\n",
+		amd + "/gpu_busy_percent":            "42\n",
+		amd + "/mem_info_vram_used":          "1073741824\n",
+		amd + "/mem_info_vram_total":         "17163091968\n",
+		amd + "/pp_dpm_sclk":                 "0: 500Mhz\n1: 1800Mhz *\n2: 2500Mhz\n",
+		amd + "/hwmon/hwmon3/temp1_input":    "54000\n",
+		amd + "/hwmon/hwmon3/power1_average": "87000000\n",
+
+		intel + "/vendor":                      "0x8086\n",
+		intel + "/device":                      "0x4680\n",
+		"/sys/class/drm/card1/gt_cur_freq_mhz": "350\n",
+		"/sys/class/drm/card1/gt_max_freq_mhz": "1450\n",
+	}, map[string]string{
+		"/sys/class/drm/card0/device":      amd,
+		"/sys/class/drm/renderD128/device": amd,
+		"/sys/class/drm/card1/device":      intel,
+		"/sys/class/drm/renderD129/device": intel,
+		amd + "/driver":                    "/sys/bus/pci/drivers/amdgpu",
+		intel + "/driver":                  "/sys/bus/pci/drivers/i915",
+	})
+
+	status, err := NewProber(root).Probe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !status.Present {
+		t.Fatal("Present = false, want true")
+	}
+	if got, want := status.Vendors, []string{"amd", "intel"}; !equal(got, want) {
+		t.Errorf("Vendors = %v, want %v", got, want)
+	}
+	if len(status.Devices) != 2 {
+		t.Fatalf("got %d devices, want 2", len(status.Devices))
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"amd render node", status.Devices[0].RenderNode, "/dev/dri/renderD128"},
+		{"amd card", status.Devices[0].Card, "card0"},
+		{"amd driver", status.Devices[0].Driver, "amdgpu"},
+		{"amd busy", deref(status.Devices[0].BusyPercent), 42},
+		{"amd vram used", deref(status.Devices[0].VRAMUsedBytes), uint64(1 << 30)},
+		{"amd vram total", deref(status.Devices[0].VRAMTotalBytes), uint64(17163091968)},
+		{"amd freq", deref(status.Devices[0].FreqMHz), 1800},
+		{"amd max freq", deref(status.Devices[0].MaxFreqMHz), 2500},
+		{"amd temperature", deref(status.Devices[0].TemperatureC), 54.0},
+		{"amd power", deref(status.Devices[0].PowerW), 87.0},
+
+		{"intel render node", status.Devices[1].RenderNode, "/dev/dri/renderD129"},
+		{"intel card", status.Devices[1].Card, "card1"},
+		{"intel freq", deref(status.Devices[1].FreqMHz), 350},
+		{"intel max freq", deref(status.Devices[1].MaxFreqMHz), 1450},
+		{"intel busy", status.Devices[1].BusyPercent == nil, true},
+		{"intel temperature", status.Devices[1].TemperatureC == nil, true},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestProbeWithoutRenderNodes(t *testing.T) {
+	status, err := NewProber(t.TempDir()).Probe()
+	if err != nil || status.Present || len(status.Devices) != 0 {
+		t.Errorf("Probe() = %+v, %v, want no GPU", status, err)
+	}
+}
+
+func TestProbeSysfsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr bool
+	}{
+		{"no sysfs", map[string]string{"/dev/dri/renderD128": ""}, false},
+		{"unreadable sysfs", map[string]string{"/dev/dri/renderD128": "", "/sys/class/drm": ""}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := NewProber(testutil.FakeTree(t, tt.files, nil)).Probe()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Probe() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !status.Present {
+				t.Error("Present = false, want true")
+			}
+		})
+	}
+}
+
+func TestReadDPMClock(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		cur, peak any
+	}{
+		{"active level", "0: 500Mhz\n1: 1800Mhz *\n", 1800, 1800},
+		{"lowest level active", "0: 500Mhz *\n1: 1800Mhz\n", 500, 1800},
+		{"none marked", "0: 500Mhz\n1: 1800Mhz\n", nil, 1800},
+		{"garbage", "not a clock\n", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "pp_dpm_sclk")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			cur, peak := readDPMClock(path)
+			if got := deref(cur); got != tt.cur {
+				t.Errorf("cur = %v, want %v", got, tt.cur)
+			}
+			if got := deref(peak); got != tt.peak {
+				t.Errorf("peak = %v, want %v", got, tt.peak)
+			}
+		})
+	}
+}
+
+// deref returns the value behind p, or untyped nil for a nil pointer.
+func deref[T any](p *T) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -1,7 +1,29 @@
 package gpu
 
+import "kit.workmate/live-agent/internal/system/pci"
+
 type Status struct {
 	Present     bool     `json:"present"`
 	Vendors     []string `json:"vendors,omitempty"`
 	RenderNodes []string `json:"render_nodes,omitempty"`
+	Devices     []Device `json:"devices,omitempty"`
+}
+
+// Device holds live telemetry for one GPU, keyed by its render node.
+// Values the driver doesn't expose are left nil.
+type Device struct {
+	RenderNode string `json:"render_node"`
+	Card       string `json:"card,omitempty"`
+	Vendor     string `json:"vendor"`
+	Driver     string `json:"driver,omitempty"`
+
+	PCI *pci.Device `json:"pci,omitempty"`
+
+	BusyPercent    *int     `json:"busy_percent,omitempty"`
+	VRAMUsedBytes  *uint64  `json:"vram_used_bytes,omitempty"`
+	VRAMTotalBytes *uint64  `json:"vram_total_bytes,omitempty"`
+	FreqMHz        *int     `json:"freq_mhz,omitempty"`
+	MaxFreqMHz     *int     `json:"max_freq_mhz,omitempty"`
+	TemperatureC   *float64 `json:"temperature_c,omitempty"`
+	PowerW         *float64 `json:"power_w,omitempty"`
 }
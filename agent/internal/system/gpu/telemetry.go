@@ -0,0 +1,193 @@
+package gpu
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"kit.workmate/live-agent/internal/system/pci"
+)
+
+// devices collects telemetry for every render node in /sys/class/drm.
+func (p *Prober) devices() ([]Device, error) {
+	drmDir := p.path("/sys/class/drm")
+
+	entries, err := readDRM(drmDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Map the PCI device behind each primary node (card0, card1, ...) to its
+	// name, connectors like card0-HDMI-A-1 are skipped.
+	cards := map[string]string{}
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, "card") || strings.Contains(name, "-") {
+			continue
+		}
+		if dev, err := filepath.EvalSymlinks(filepath.Join(drmDir, name, "device")); err == nil {
+			cards[dev] = name
+		}
+	}
+
+	var devices []Device
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name(), "renderD") {
+			continue
+		}
+
+		deviceDir := filepath.Join(drmDir, e.Name(), "device")
+		resolved, err := filepath.EvalSymlinks(deviceDir)
+		if err != nil {
+			continue
+		}
+
+		dev := Device{
+			RenderNode: "/dev/dri/" + e.Name(),
+			Card:       cards[resolved],
+			Vendor:     vendorName(readString(filepath.Join(deviceDir, "vendor"))),
+			Driver:     linkName(filepath.Join(deviceDir, "driver")),
+		}
+
+		if info, ok := pci.FromSysfs(deviceDir); ok {
+			dev.PCI = &info
+		}
+
+		// amdgpu
+		dev.BusyPercent = readInt(filepath.Join(deviceDir, "gpu_busy_percent"))
+		dev.VRAMUsedBytes = readUint(filepath.Join(deviceDir, "mem_info_vram_used"))
+		dev.VRAMTotalBytes = readUint(filepath.Join(deviceDir, "mem_info_vram_total"))
+		dev.FreqMHz, dev.MaxFreqMHz = readDPMClock(filepath.Join(deviceDir, "pp_dpm_sclk"))
+
+		// i915 exposes its frequencies on the card node, not the PCI device
+		if dev.Card != "" {
+			cardDir := filepath.Join(drmDir, dev.Card)
+			if freq := readInt(filepath.Join(cardDir, "gt_cur_freq_mhz")); freq != nil {
+				dev.FreqMHz = freq
+			}
+			if freq := readInt(filepath.Join(cardDir, "gt_max_freq_mhz")); freq != nil {
+				dev.MaxFreqMHz = freq
+			}
+		}
+
+		dev.TemperatureC, dev.PowerW = readHwmon(filepath.Join(deviceDir, "hwmon"))
+
+		devices = append(devices, dev)
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].RenderNode < devices[j].RenderNode
+	})
+
+	return devices, nil
+}
+
+// readDRM lists /sys/class/drm. A container can have render nodes without
+// sysfs, which isn't an error, only a sysfs that can't be read is.
+func readDRM(dir string) ([]os.DirEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return entries, err
+}
+
+// readHwmon reads the first temperature (millidegrees) and power draw
+// (microwatts) sensor of a device.
+func readHwmon(dir string) (*float64, *float64) {
+	monitors, _ := filepath.Glob(filepath.Join(dir, "hwmon*"))
+	sort.Strings(monitors)
+
+	var temp, power *float64
+	for _, mon := range monitors {
+		if temp == nil {
+			if v := readInt(filepath.Join(mon, "temp1_input")); v != nil {
+				c := float64(*v) / 1000
+				temp = &c
+			}
+		}
+		if power == nil {
+			for _, name := range []string{"power1_average", "power1_input"} {
+				if v := readUint(filepath.Join(mon, name)); v != nil {
+					w := float64(*v) / 1e6
+					power = &w
+					break
+				}
+			}
+		}
+	}
+
+	return temp, power
+}
+
+// readDPMClock parses amdgpu's pp_dpm_sclk, where the active level is
+// marked with an asterisk:
+//
+//	0: 500Mhz
+//	1: 1800Mhz *
+func readDPMClock(path string) (cur, peak *int) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		mhz, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(fields[1]), "mhz"))
+		if err != nil {
+			continue
+		}
+
+		if peak == nil || mhz > *peak {
+			peak = &mhz
+		}
+		if len(fields) > 2 && fields[2] == "*" {
+			v := mhz
+			cur = &v
+		}
+	}
+
+	return cur, peak
+}
+
+func readString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func readInt(path string) *int {
+	v, err := strconv.Atoi(readString(path))
+	if err != nil {
+		return nil
+	}
+	return &v
+}
+
+func readUint(path string) *uint64 {
+	v, err := strconv.ParseUint(readString(path), 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
+
+func linkName(path string) string {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(target)
+}
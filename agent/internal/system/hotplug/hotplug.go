@@ -0,0 +1,60 @@
+package hotplug
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Action sagt, was mit einem Geräteknoten passiert ist.
+type Action string
+
+const (
+	ActionAdded   Action = "added"
+	ActionRemoved Action = "removed"
+	// ActionChanged kommt bei geänderten Attributen, z.B. wenn udev direkt
+	// nach dem Anlegen die Rechte eines Knotens setzt.
+	ActionChanged Action = "changed"
+)
+
+// Kind fasst Geräteknoten nach Subsystem zusammen.
+type Kind string
+
+const (
+	KindVideo Kind = "video"
+	KindAudio Kind = "audio"
+	KindDRM   Kind = "drm"
+)
+
+// Event ist ein einzelner Geräteknoten, der auftaucht oder verschwindet.
+type Event struct {
+	Time   time.Time `json:"time"`
+	Action Action    `json:"action"`
+	Kind   Kind      `json:"kind"`
+	Path   string    `json:"path"`
+}
+
+// Dirs sind die Verzeichnisse, in denen auf Geräteknoten geachtet wird.
+var Dirs = []string{"/dev", "/dev/snd", "/dev/dri"}
+
+// ErrUnsupported kommt auf Plattformen ohne inotify.
+var ErrUnsupported = errors.New("hotplug detection not supported on this platform")
+
+// Classify gibt die Art eines Geräteknotens zurück, oder false, wenn der
+// Knoten den Agent nicht interessiert.
+func Classify(path string) (Kind, bool) {
+	dir, name := filepath.Split(path)
+	dir = filepath.Clean(dir)
+
+	switch {
+	case dir == "/dev" && strings.HasPrefix(name, "video"):
+		return KindVideo, true
+	case dir == "/dev/snd" && (strings.HasPrefix(name, "pcmC") || strings.HasPrefix(name, "controlC")):
+		return KindAudio, true
+	case dir == "/dev/dri" && (strings.HasPrefix(name, "card") || strings.HasPrefix(name, "renderD")):
+		return KindDRM, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package hotplug
+
+// Watcher gibt es auf dieser Plattform nicht.
+type Watcher struct{}
+
+// Watch schlägt außerhalb von Linux immer mit ErrUnsupported fehl.
+func Watch(handler func(Event)) (*Watcher, error) {
+	return nil, ErrUnsupported
+}
+
+// Close tut nichts.
+func (w *Watcher) Close() error {
+	return nil
+}
@@ -0,0 +1,192 @@
+//go:build linux
+
+package hotplug
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const watchMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MOVED_FROM |
+	syscall.IN_MOVED_TO | syscall.IN_ATTRIB | syscall.IN_DELETE_SELF
+
+// Watcher meldet per inotify, wenn Geräteknoten kommen und gehen.
+type Watcher struct {
+	file    *os.File
+	fd      int
+	handler func(Event)
+
+	mu   sync.Mutex
+	dirs map[int32]string
+	done chan struct{}
+}
+
+// Watch beobachtet Dirs und ruft handler für jedes relevante Ereignis auf.
+// Verzeichnisse, die es noch nicht gibt, kommen dazu, sobald sie angelegt werden.
+func Watch(handler func(Event)) (*Watcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		// Nicht blockierend, damit der Runtime-Poller Read bei Close abbrechen kann
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		fd:      fd,
+		handler: handler,
+		dirs:    map[int32]string{},
+		done:    make(chan struct{}),
+	}
+
+	for _, dir := range Dirs {
+		if err := w.add(dir); err != nil && !errors.Is(err, syscall.ENOENT) {
+			w.file.Close()
+			return nil, err
+		}
+	}
+
+	go w.run()
+	return w, nil
+}
+
+func (w *Watcher) add(dir string) error {
+	wd, err := syscall.InotifyAddWatch(w.fd, dir, watchMask)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	w.dirs[int32(wd)] = dir
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *Watcher) run() {
+	defer close(w.done)
+
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				log.Printf("hotplug watcher stopped: %v", err)
+			}
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			raw := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameBytes := buf[offset+syscall.SizeofInotifyEvent : offset+syscall.SizeofInotifyEvent+int(raw.Len)]
+			offset += syscall.SizeofInotifyEvent + int(raw.Len)
+
+			w.handle(raw.Wd, raw.Mask, cString(nameBytes))
+		}
+	}
+}
+
+func (w *Watcher) handle(wd int32, mask uint32, name string) {
+	w.mu.Lock()
+	dir, ok := w.dirs[wd]
+	if ok && mask&(syscall.IN_DELETE_SELF|syscall.IN_IGNORED) != 0 {
+		delete(w.dirs, wd)
+	}
+	w.mu.Unlock()
+
+	if !ok || name == "" {
+		return
+	}
+
+	path := filepath.Join(dir, name)
+
+	// /dev/snd und /dev/dri gibt es erst ab dem ersten Gerät
+	if mask&syscall.IN_ISDIR != 0 {
+		if mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 && isWatchedDir(path) {
+			w.addNew(path)
+		}
+		return
+	}
+
+	action := ActionChanged
+	switch {
+	case mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0:
+		action = ActionAdded
+	case mask&(syscall.IN_DELETE|syscall.IN_MOVED_FROM) != 0:
+		action = ActionRemoved
+	}
+
+	w.emit(action, path)
+}
+
+// addNew beobachtet ein gerade angelegtes Verzeichnis. Knoten, die vor
+// dem Watch darin angelegt wurden, haben kein Ereignis ausgelöst und
+// werden deshalb danach eingelesen. Doppelte Meldungen sind harmlos, es
+// wird ohnehin neu abgefragt.
+func (w *Watcher) addNew(dir string) {
+	if err := w.add(dir); err != nil {
+		log.Printf("hotplug: cannot watch %s: %v", dir, err)
+		return
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		log.Printf("hotplug: cannot read %s: %v", dir, err)
+		return
+	}
+
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		if e.IsDir() {
+			if isWatchedDir(path) {
+				w.addNew(path)
+			}
+			continue
+		}
+		w.emit(ActionAdded, path)
+	}
+}
+
+func (w *Watcher) emit(action Action, path string) {
+	kind, ok := Classify(path)
+	if !ok {
+		return
+	}
+
+	w.handler(Event{
+		Time:   time.Now(),
+		Action: action,
+		Kind:   kind,
+		Path:   path,
+	})
+}
+
+// Close beendet den Watcher und wartet auf das Ende der Leseschleife.
+func (w *Watcher) Close() error {
+	err := w.file.Close()
+	<-w.done
+	return err
+}
+
+func isWatchedDir(path string) bool {
+	for _, dir := range Dirs {
+		if dir == path {
+			return true
+		}
+	}
+	return false
+}
+
+func cString(b []byte) string {
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i])
+		}
+	}
+	return string(b)
+}
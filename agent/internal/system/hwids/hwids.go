@@ -0,0 +1,140 @@
+// Package hwids parses the pci.ids and usb.ids hardware databases
+// maintained by the PCI and USB ID projects.
+package hwids
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+)
+
+// Database maps vendor and device IDs (lowercase hex, no 0x) to names.
+type Database struct {
+	// Source is the file the database was loaded from, or "embedded".
+	Source  string
+	vendors map[string]string
+	devices map[string]string
+}
+
+// Load parses the first readable file in paths. Files ending in .gz are
+// decompressed. If none can be read, the fallback data is used instead.
+func Load(paths []string, fallback []byte) *Database {
+	for _, path := range paths {
+		data, err := readFile(path)
+		if err != nil {
+			continue
+		}
+
+		db := Parse(data)
+		db.Source = path
+		return db
+	}
+
+	db := Parse(fallback)
+	db.Source = "embedded"
+	return db
+}
+
+func readFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	return io.ReadAll(r)
+}
+
+// Parse reads the vendor section of an ids file:
+//
+//	8086  Intel Corporation
+//		1912  HD Graphics 530
+//			1028 06d9  Subsystem (ignored)
+//
+// Parsing stops at the first line that is neither a comment, a vendor
+// nor an indented entry, which is where the class lists begin.
+func Parse(data []byte) *Database {
+	db := &Database{
+		vendors: map[string]string{},
+		devices: map[string]string{},
+	}
+
+	var vendor string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+
+		case strings.HasPrefix(line, "\t\t"):
+			continue // subsystem / interface
+
+		case strings.HasPrefix(line, "\t"):
+			if vendor == "" {
+				continue
+			}
+			id, name, ok := splitEntry(line[1:])
+			if ok {
+				db.devices[vendor+":"+id] = name
+			}
+
+		default:
+			id, name, ok := splitEntry(line)
+			if !ok {
+				return db // end of vendor section
+			}
+			vendor = id
+			db.vendors[id] = name
+		}
+	}
+
+	return db
+}
+
+// splitEntry splits "1912  HD Graphics 530" into ID and name.
+func splitEntry(line string) (string, string, bool) {
+	if len(line) < 6 || line[4] != ' ' || !isHex(line[:4]) {
+		return "", "", false
+	}
+	return strings.ToLower(line[:4]), strings.TrimSpace(line[5:]), true
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// Vendor returns the vendor name, or "" if unknown.
+func (db *Database) Vendor(vendor string) string {
+	return db.vendors[normalize(vendor)]
+}
+
+// Device returns the device name, or "" if unknown.
+func (db *Database) Device(vendor, device string) string {
+	return db.devices[normalize(vendor)+":"+normalize(device)]
+}
+
+// normalize turns "0x10DE" into "10de".
+func normalize(id string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(id), "0x"))
+}
@@ -0,0 +1,29 @@
+//go:build linux
+
+package load
+
+import (
+	"math"
+	"syscall"
+)
+
+// DiskUsage reports the space available to unprivileged users, which is
+// what OBS running as a normal user can actually write.
+func DiskUsage(path string) Disk {
+	disk := Disk{Path: path}
+
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		disk.Error = err.Error()
+		return disk
+	}
+
+	disk.TotalBytes = st.Blocks * uint64(st.Bsize)
+	disk.FreeBytes = st.Bavail * uint64(st.Bsize)
+	if disk.TotalBytes > 0 {
+		used := disk.TotalBytes - st.Bfree*uint64(st.Bsize)
+		disk.UsedPercent = math.Round(float64(used)/float64(disk.TotalBytes)*1000) / 10
+	}
+
+	return disk
+}
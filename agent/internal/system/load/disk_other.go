@@ -0,0 +1,8 @@
+//go:build !linux
+
+package load
+
+// DiskUsage is only implemented on Linux.
+func DiskUsage(path string) Disk {
+	return Disk{Path: path, Error: "not supported on this platform"}
+}
@@ -0,0 +1,76 @@
+package load
+
+import "fmt"
+
+// Status is a snapshot of how busy the machine is.
+type Status struct {
+	CPU      CPU         `json:"cpu"`
+	Memory   Memory      `json:"memory"`
+	Disks    []Disk      `json:"disks"`
+	Network  []Interface `json:"network"`
+	Warnings []string    `json:"warnings,omitempty"`
+}
+
+type CPU struct {
+	// UsagePercent is averaged over all cores, 100 means fully busy.
+	UsagePercent float64   `json:"usage_percent"`
+	Cores        []float64 `json:"cores"`
+	Load1        float64   `json:"load1"`
+	Load5        float64   `json:"load5"`
+	Load15       float64   `json:"load15"`
+}
+
+type Memory struct {
+	TotalBytes     uint64  `json:"total_bytes"`
+	AvailableBytes uint64  `json:"available_bytes"`
+	UsedBytes      uint64  `json:"used_bytes"`
+	UsedPercent    float64 `json:"used_percent"`
+	SwapTotalBytes uint64  `json:"swap_total_bytes"`
+	SwapUsedBytes  uint64  `json:"swap_used_bytes"`
+}
+
+type Disk struct {
+	Path        string  `json:"path"`
+	TotalBytes  uint64  `json:"total_bytes"`
+	FreeBytes   uint64  `json:"free_bytes"`
+	UsedPercent float64 `json:"used_percent"`
+	Error       string  `json:"error,omitempty"`
+}
+
+type Interface struct {
+	Name          string  `json:"name"`
+	RxBytes       uint64  `json:"rx_bytes"`
+	TxBytes       uint64  `json:"tx_bytes"`
+	RxBytesPerSec float64 `json:"rx_bytes_per_sec"`
+	TxBytesPerSec float64 `json:"tx_bytes_per_sec"`
+}
+
+// Thresholds above which a warning is raised. Zero disables a check.
+type Thresholds struct {
+	CPUPercent    float64
+	MemoryPercent float64
+	DiskFreeBytes uint64
+}
+
+// warnings compares a sample against the thresholds.
+func (t Thresholds) warnings(s Status) []string {
+	var warnings []string
+
+	if t.CPUPercent > 0 && s.CPU.UsagePercent >= t.CPUPercent {
+		warnings = append(warnings, fmt.Sprintf("CPU usage %.0f%% is above %.0f%%", s.CPU.UsagePercent, t.CPUPercent))
+	}
+
+	if t.MemoryPercent > 0 && s.Memory.UsedPercent >= t.MemoryPercent {
+		warnings = append(warnings, fmt.Sprintf("memory usage %.0f%% is above %.0f%%", s.Memory.UsedPercent, t.MemoryPercent))
+	}
+
+	if t.DiskFreeBytes > 0 {
+		for _, d := range s.Disks {
+			if d.Error == "" && d.FreeBytes < t.DiskFreeBytes {
+				warnings = append(warnings, fmt.Sprintf("only %d MB free on %s", d.FreeBytes/(1024*1024), d.Path))
+			}
+		}
+	}
+
+	return warnings
+}
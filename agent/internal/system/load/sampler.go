@@ -0,0 +1,227 @@
+package load
+
+import (
+	"bufio"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sampler reads load counters from ProcRoot. CPU usage and network rates
+// are calculated against the previous sample, so the first one reports 0.
+type Sampler struct {
+	ProcRoot   string
+	DiskPaths  []string
+	Thresholds Thresholds
+
+	mu      sync.Mutex
+	prevCPU map[string]cpuTimes
+	prevNet map[string][2]uint64
+	prevAt  time.Time
+}
+
+type cpuTimes struct {
+	busy, total uint64
+}
+
+func NewSampler(procRoot string, diskPaths []string, thresholds Thresholds) *Sampler {
+	return &Sampler{
+		ProcRoot:   procRoot,
+		DiskPaths:  diskPaths,
+		Thresholds: thresholds,
+	}
+}
+
+// Sample reads the counters. The error is set if one of the /proc files
+// can't be read; disks that can't be read only carry their own error.
+func (s *Sampler) Sample() (Status, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	cpu, cpuErr := s.cpu()
+	memory, memoryErr := s.memory()
+	network, networkErr := s.network(now)
+	if err := errors.Join(cpuErr, memoryErr, networkErr); err != nil {
+		return Status{}, err
+	}
+
+	status := Status{
+		CPU:     cpu,
+		Memory:  memory,
+		Disks:   make([]Disk, 0, len(s.DiskPaths)),
+		Network: network,
+	}
+
+	for _, path := range s.DiskPaths {
+		status.Disks = append(status.Disks, DiskUsage(path))
+	}
+
+	status.Warnings = s.Thresholds.warnings(status)
+	s.prevAt = now
+
+	return status, nil
+}
+
+// cpu reads /proc/stat, where the "cpu" line is the sum of all "cpuN" lines.
+func (s *Sampler) cpu() (CPU, error) {
+	cpu := CPU{Cores: []float64{}}
+
+	f, err := os.Open(filepath.Join(s.ProcRoot, "stat"))
+	if err != nil {
+		return cpu, err
+	}
+	defer f.Close()
+
+	current := map[string]cpuTimes{}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+
+		var t cpuTimes
+		for i, v := range fields[1:] {
+			n, _ := strconv.ParseUint(v, 10, 64)
+			// guest and guest_nice are already part of user and nice
+			if i >= 8 {
+				break
+			}
+			t.total += n
+			// idle and iowait
+			if i != 3 && i != 4 {
+				t.busy += n
+			}
+		}
+
+		name := fields[0]
+		current[name] = t
+
+		usage := usagePercent(s.prevCPU[name], t)
+		if name == "cpu" {
+			cpu.UsagePercent = usage
+		} else {
+			cpu.Cores = append(cpu.Cores, usage)
+		}
+	}
+
+	s.prevCPU = current
+
+	if data, err := os.ReadFile(filepath.Join(s.ProcRoot, "loadavg")); err == nil {
+		fields := strings.Fields(string(data))
+		if len(fields) >= 3 {
+			cpu.Load1, _ = strconv.ParseFloat(fields[0], 64)
+			cpu.Load5, _ = strconv.ParseFloat(fields[1], 64)
+			cpu.Load15, _ = strconv.ParseFloat(fields[2], 64)
+		}
+	}
+
+	return cpu, nil
+}
+
+func usagePercent(prev, cur cpuTimes) float64 {
+	if prev.total == 0 || cur.total <= prev.total || cur.busy < prev.busy {
+		return 0
+	}
+	return round1(float64(cur.busy-prev.busy) / float64(cur.total-prev.total) * 100)
+}
+
+func (s *Sampler) memory() (Memory, error) {
+	f, err := os.Open(filepath.Join(s.ProcRoot, "meminfo"))
+	if err != nil {
+		return Memory{}, err
+	}
+	defer f.Close()
+
+	values := map[string]uint64{}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		kb, _ := strconv.ParseUint(fields[1], 10, 64)
+		values[strings.TrimSuffix(fields[0], ":")] = kb * 1024
+	}
+
+	m := Memory{
+		TotalBytes:     values["MemTotal"],
+		AvailableBytes: values["MemAvailable"],
+		SwapTotalBytes: values["SwapTotal"],
+	}
+	if m.TotalBytes >= m.AvailableBytes {
+		m.UsedBytes = m.TotalBytes - m.AvailableBytes
+	}
+	if m.TotalBytes > 0 {
+		m.UsedPercent = round1(float64(m.UsedBytes) / float64(m.TotalBytes) * 100)
+	}
+	if m.SwapTotalBytes >= values["SwapFree"] {
+		m.SwapUsedBytes = m.SwapTotalBytes - values["SwapFree"]
+	}
+
+	return m, nil
+}
+
+// network reads the per-interface byte counters from /proc/net/dev:
+//
+//	eth0: 1234 10 0 0 0 0 0 0 5678 20 0 0 0 0 0 0
+func (s *Sampler) network(now time.Time) ([]Interface, error) {
+	interfaces := []Interface{}
+
+	f, err := os.Open(filepath.Join(s.ProcRoot, "net", "dev"))
+	if err != nil {
+		return interfaces, err
+	}
+	defer f.Close()
+
+	elapsed := now.Sub(s.prevAt).Seconds()
+	current := map[string][2]uint64{}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, counters, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		name = strings.TrimSpace(name)
+
+		fields := strings.Fields(counters)
+		if name == "lo" || len(fields) < 9 {
+			continue
+		}
+
+		rx, _ := strconv.ParseUint(fields[0], 10, 64)
+		tx, _ := strconv.ParseUint(fields[8], 10, 64)
+		current[name] = [2]uint64{rx, tx}
+
+		iface := Interface{Name: name, RxBytes: rx, TxBytes: tx}
+		if prev, ok := s.prevNet[name]; ok && elapsed > 0 && rx >= prev[0] && tx >= prev[1] {
+			iface.RxBytesPerSec = round1(float64(rx-prev[0]) / elapsed)
+			iface.TxBytesPerSec = round1(float64(tx-prev[1]) / elapsed)
+		}
+
+		interfaces = append(interfaces, iface)
+	}
+
+	s.prevNet = current
+
+	sort.Slice(interfaces, func(i, j int) bool {
+		return interfaces[i].Name < interfaces[j].Name
+	})
+
+	return interfaces, nil
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
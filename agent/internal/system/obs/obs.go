@@ -1,5 +1,41 @@
 package obs
 
+import "time"
+
 type Status struct {
-	Running bool
+	Running   bool
+	Process   *Process
+	WebSocket *WebSocketConfig
+}
+
+// Process describes a running OBS instance.
+type Process struct {
+	PID         int    `json:"pid"`
+	Name        string `json:"name"`
+	Exe         string `json:"exe,omitempty"`
+	InstallType string `json:"install_type"`
+
+	StartedAt     time.Time `json:"started_at"`
+	UptimeSeconds float64   `json:"uptime_seconds"`
+	// CPUPercent is measured between two probes, 100 means one full core.
+	CPUPercent float64 `json:"cpu_percent"`
+	RSSBytes   uint64  `json:"rss_bytes"`
+	Threads    int     `json:"threads"`
+
+	Args           []string `json:"args"`
+	Profile        string   `json:"profile,omitempty"`
+	Collection     string   `json:"collection,omitempty"`
+	Scene          string   `json:"scene,omitempty"`
+	StartStreaming bool     `json:"start_streaming"`
+	StartRecording bool     `json:"start_recording"`
+	MinimizeToTray bool     `json:"minimize_to_tray"`
+	Portable       bool     `json:"portable"`
 }
+
+// Install types
+const (
+	InstallNative   = "native"
+	InstallFlatpak  = "flatpak"
+	InstallSnap     = "snap"
+	InstallAppImage = "appimage"
+)
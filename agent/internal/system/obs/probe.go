@@ -1,16 +1,87 @@
 package obs
 
 import (
+	"bufio"
+	"math"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
-func Probe() Status {
-	entries, err := os.ReadDir("/proc")
+// clockTicks is USER_HZ, which is 100 on every Linux architecture we run on.
+const clockTicks = 100
+
+// ProcessNames are the executable names OBS is known by.
+var ProcessNames = []string{"obs", "obs64", "obs-studio"}
+
+// Prober finds OBS below ProcRoot and keeps the previous CPU sample to
+// calculate usage between two probes.
+type Prober struct {
+	ProcRoot string
+
+	mu   sync.Mutex
+	prev cpuSample
+	// libobs caches the maps check per PID, see mapsOBS
+	libobs map[string]libobsCheck
+}
+
+type libobsCheck struct {
+	startTime string
+	mapped    bool
+}
+
+type cpuSample struct {
+	pid   int
+	ticks uint64
+	at    time.Time
+}
+
+func NewProber(procRoot string) *Prober {
+	return &Prober{ProcRoot: procRoot}
+}
+
+var defaultProber = NewProber("/proc")
+
+func Probe() (Status, error) {
+	return defaultProber.Probe()
+}
+
+// Probe looks for a running OBS. The error is set if the process list
+// can't be read, not if OBS isn't running.
+func (p *Prober) Probe() (Status, error) {
+	pids, err := p.find()
 	if err != nil {
-		return Status{Running: false}
+		return Status{}, err
+	}
+	if len(pids) == 0 {
+		ws, _ := p.webSocketConfig(nil)
+		return Status{Running: false, WebSocket: ws}, nil
+	}
+
+	proc, err := p.inspect(pids[0])
+	if err != nil {
+		// Process exited while we were looking at it
+		return Status{Running: true}, nil
 	}
 
+	ws, _ := p.webSocketConfig(proc)
+	return Status{Running: true, Process: proc, WebSocket: ws}, nil
+}
+
+// find returns the PIDs of all OBS processes, lowest first. With several
+// instances that is the oldest one in practice.
+func (p *Prober) find() ([]int, error) {
+	entries, err := os.ReadDir(p.ProcRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var pids []int
+	var others []string
 	for _, e := range entries {
 		if !e.IsDir() {
 			continue
@@ -21,18 +92,277 @@ func Probe() Status {
 			continue
 		}
 
-		comm, err := os.ReadFile("/proc/" + pid + "/comm")
-		if err != nil {
+		if p.isOBS(pid) {
+			n, _ := strconv.Atoi(pid)
+			pids = append(pids, n)
+		} else {
+			others = append(others, pid)
+		}
+	}
+
+	// A renamed or wrapped binary is still found by the libobs it has
+	// mapped, but only look if nothing matched by name
+	if len(pids) == 0 {
+		for _, pid := range p.mapsOBS(others) {
+			n, _ := strconv.Atoi(pid)
+			pids = append(pids, n)
+		}
+	}
+
+	sort.Ints(pids)
+	return pids, nil
+}
+
+// isOBS matches the process name, the executable and argv[0] against
+// ProcessNames.
+func (p *Prober) isOBS(pid string) bool {
+	dir := filepath.Join(p.ProcRoot, pid)
+
+	comm, err := os.ReadFile(filepath.Join(dir, "comm"))
+	if err != nil {
+		return false
+	}
+
+	candidates := []string{strings.TrimSpace(string(comm))}
+	if exe, err := os.Readlink(filepath.Join(dir, "exe")); err == nil {
+		exe = strings.TrimSuffix(exe, " (deleted)")
+		candidates = append(candidates, filepath.Base(exe))
+		// /usr/bin/obs may be a link to a versioned binary or the other way round
+		if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+			candidates = append(candidates, filepath.Base(resolved))
+		}
+	}
+	if args := readCmdline(filepath.Join(dir, "cmdline")); len(args) > 0 {
+		candidates = append(candidates, filepath.Base(args[0]))
+	}
+
+	for _, c := range candidates {
+		for _, name := range ProcessNames {
+			if strings.EqualFold(c, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mapsOBS returns the pids that have libobs mapped. Reading maps takes
+// the process's mmap lock, so every process is only checked once: the
+// result is kept per PID and start time, which tells a reused PID apart.
+func (p *Prober) mapsOBS(pids []string) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var found []string
+	checked := make(map[string]libobsCheck, len(pids))
+	for _, pid := range pids {
+		dir := filepath.Join(p.ProcRoot, pid)
+		start, ok := startTime(filepath.Join(dir, "stat"))
+		if !ok {
+			continue
+		}
+
+		check, ok := p.libobs[pid]
+		if !ok || check.startTime != start {
+			check = libobsCheck{startTime: start, mapped: mapsLibOBS(filepath.Join(dir, "maps"))}
+		}
+		checked[pid] = check
+
+		if check.mapped {
+			found = append(found, pid)
+		}
+	}
+
+	// Exited processes drop out
+	p.libobs = checked
+	return found
+}
+
+// startTime returns field 22 of /proc/<pid>/stat, the start time in
+// clock ticks after boot.
+func startTime(path string) (string, bool) {
+	stat, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+
+	end := strings.LastIndexByte(string(stat), ')')
+	if end < 0 {
+		return "", false
+	}
+	fields := strings.Fields(string(stat[end+1:]))
+	if len(fields) < 20 {
+		return "", false
+	}
+	return fields[22-3], true
+}
+
+// mapsLibOBS tells whether libobs.so is mapped. Only OBS itself and its
+// helpers load it. Without root, maps of other users' processes can't be
+// read and count as not mapped.
+func mapsLibOBS(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
 			continue
 		}
+		if strings.HasPrefix(filepath.Base(fields[5]), "libobs.so") {
+			return true
+		}
+	}
+	return false
+}
 
-		name := strings.TrimSpace(string(comm))
-		if name == "obs" || name == "obs64" {
-			return Status{Running: true}
+func (p *Prober) inspect(pid int) (*Process, error) {
+	dir := filepath.Join(p.ProcRoot, strconv.Itoa(pid))
+
+	stat, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return nil, err
+	}
+
+	// The name in parentheses may contain spaces, so split after it
+	end := strings.LastIndexByte(string(stat), ')')
+	if end < 0 {
+		return nil, os.ErrInvalid
+	}
+	name := string(stat[strings.IndexByte(string(stat), '(')+1 : end])
+	fields := strings.Fields(string(stat[end+1:]))
+	if len(fields) < 22 {
+		return nil, os.ErrInvalid
+	}
+
+	// fields[0] is field 3 (state) in proc(5)
+	field := func(n int) uint64 {
+		v, _ := strconv.ParseUint(fields[n-3], 10, 64)
+		return v
+	}
+
+	now := time.Now()
+	ticks := field(14) + field(15)
+	startedAt := p.bootTime().Add(time.Duration(field(22)) * time.Second / clockTicks)
+
+	proc := &Process{
+		PID:           pid,
+		Name:          name,
+		StartedAt:     startedAt,
+		UptimeSeconds: now.Sub(startedAt).Round(time.Second).Seconds(),
+		RSSBytes:      field(24) * uint64(os.Getpagesize()),
+		Threads:       int(field(20)),
+		Args:          readCmdline(filepath.Join(dir, "cmdline")),
+	}
+
+	if exe, err := os.Readlink(filepath.Join(dir, "exe")); err == nil {
+		proc.Exe = exe
+	}
+
+	proc.InstallType = p.installType(pid)
+
+	parseArgs(proc)
+
+	p.mu.Lock()
+	if p.prev.pid == pid && ticks >= p.prev.ticks {
+		elapsed := now.Sub(p.prev.at).Seconds()
+		if elapsed > 0 {
+			used := float64(ticks-p.prev.ticks) / clockTicks
+			proc.CPUPercent = math.Round(used/elapsed*1000) / 10
+		}
+	}
+	p.prev = cpuSample{pid: pid, ticks: ticks, at: now}
+	p.mu.Unlock()
+
+	return proc, nil
+}
+
+// bootTime reads btime from /proc/stat.
+func (p *Prober) bootTime() time.Time {
+	data, err := os.ReadFile(filepath.Join(p.ProcRoot, "stat"))
+	if err != nil {
+		return time.Time{}
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if v, ok := strings.CutPrefix(line, "btime "); ok {
+			secs, _ := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			return time.Unix(secs, 0)
+		}
+	}
+	return time.Time{}
+}
+
+// installType tells Flatpak, Snap and AppImage installs from native ones.
+func (p *Prober) installType(pid int) string {
+	dir := filepath.Join(p.ProcRoot, strconv.Itoa(pid))
+	exe, _ := os.Readlink(filepath.Join(dir, "exe"))
+	data, _ := os.ReadFile(filepath.Join(dir, "cgroup"))
+	cgroup := string(data)
+
+	switch {
+	case strings.Contains(cgroup, "app-flatpak-") || strings.HasPrefix(exe, "/app/"):
+		return InstallFlatpak
+	case strings.HasPrefix(exe, "/snap/") || strings.Contains(cgroup, "snap."):
+		return InstallSnap
+	case strings.Contains(exe, "/.mount_"):
+		return InstallAppImage
+	default:
+		return InstallNative
+	}
+}
+
+// parseArgs picks the OBS command line options we care about.
+func parseArgs(proc *Process) {
+	args := proc.Args
+	if len(args) > 0 {
+		args = args[1:]
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		key, value, hasValue := strings.Cut(arg, "=")
+		next := func() string {
+			if hasValue {
+				return value
+			}
+			if i+1 < len(args) {
+				i++
+				return args[i]
+			}
+			return ""
+		}
+
+		switch key {
+		case "--profile":
+			proc.Profile = next()
+		case "--collection":
+			proc.Collection = next()
+		case "--scene":
+			proc.Scene = next()
+		case "--startstreaming":
+			proc.StartStreaming = true
+		case "--startrecording":
+			proc.StartRecording = true
+		case "--minimize-to-tray":
+			proc.MinimizeToTray = true
+		case "--portable", "-p":
+			proc.Portable = true
 		}
 	}
+}
 
-	return Status{Running: false}
+func readCmdline(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimRight(string(data), "\x00"), "\x00")
 }
 
 func isNumeric(s string) bool {
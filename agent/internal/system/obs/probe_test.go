@@ -0,0 +1,189 @@
+package obs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"kit.workmate/live-agent/internal/testutil"
+)
+
+// stat is /proc/<pid>/stat with 1.5s of CPU time, 30 threads, started
+// 5s after boot and 1000 resident pages.
+func stat(pid, comm string) string {
+	return pid + " (" + comm + ") S 1 1 1 0 -1 4194560 0 0 0 0 100 50 0 0 20 0 30 0 500 0 1000\n"
+}
+
+const libobsMaps = "7f2a1c000000-7f2a1c0a4000 r--p 00000000 103:02 1311234 /usr/lib/x86_64-linux-gnu/libobs.so.30\n" +
+	"7f2a1d000000-7f2a1d020000 r-xp 00000000 103:02 1311240 /usr/lib/x86_64-linux-gnu/libobs-frontend-api.so.30\n"
+
+func TestProbeFind(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		links map[string]string
+		want  []int
+	}{
+		{
+			name: "by name",
+			files: map[string]string{
+				"proc/1200/comm":    "obs\n",
+				"proc/1200/cmdline": "obs\x00--startstreaming\x00",
+				"proc/900/comm":     "firefox\n",
+				"proc/900/maps":     "55d0c0000000-55d0c0100000 r-xp 00000000 103:02 42 /usr/lib/firefox/firefox\n",
+			},
+			want: []int{1200},
+		},
+		{
+			name: "renamed binary with libobs mapped",
+			files: map[string]string{
+				"proc/1300/comm":        "studio\n",
+				"proc/1300/stat":        stat("1300", "studio"),
+				"proc/1300/cmdline":     "/opt/studio/bin/studio\x00",
+				"proc/1300/maps":        libobsMaps,
+				"opt/studio/bin/studio": "",
+			},
+			links: map[string]string{"proc/1300/exe": "opt/studio/bin/studio"},
+			want:  []int{1300},
+		},
+		{
+			name: "wrapper link to the OBS binary",
+			files: map[string]string{
+				"proc/1400/comm":           "streamer\n",
+				"proc/1400/cmdline":        "/usr/local/bin/streamer\x00",
+				"usr/lib/obs-studio/obs64": "",
+			},
+			links: map[string]string{
+				"usr/local/bin/streamer": "usr/lib/obs-studio/obs64",
+				"proc/1400/exe":          "usr/local/bin/streamer",
+			},
+			want: []int{1400},
+		},
+		{
+			name: "only the frontend API mapped",
+			files: map[string]string{
+				"proc/1500/comm": "obs-plugin-host\n",
+				"proc/1500/stat": stat("1500", "obs-plugin-host"),
+				"proc/1500/maps": "7f2a1d000000-7f2a1d020000 r-xp 00000000 103:02 1311240 /usr/lib/x86_64-linux-gnu/libobs-frontend-api.so.30\n",
+			},
+		},
+		{
+			name: "several instances, lowest first",
+			files: map[string]string{
+				"proc/2000/comm": "obs\n",
+				"proc/1999/comm": "obs-studio\n",
+				"proc/self/comm": "obs\n",
+			},
+			want: []int{1999, 2000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := testutil.FakeTree(t, tt.files, tt.links)
+
+			pids, err := NewProber(filepath.Join(root, "proc")).find()
+			if err != nil {
+				t.Fatalf("find() error = %v", err)
+			}
+			if len(pids) != len(tt.want) {
+				t.Fatalf("find() = %v, want %v", pids, tt.want)
+			}
+			for i := range tt.want {
+				if pids[i] != tt.want[i] {
+					t.Errorf("find() = %v, want %v", pids, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFindChecksMapsOnce(t *testing.T) {
+	root := testutil.FakeTree(t, map[string]string{
+		"proc/1300/comm": "studio\n",
+		"proc/1300/stat": stat("1300", "studio"),
+		"proc/1300/maps": "55d0c0000000-55d0c0100000 r-xp 00000000 103:02 42 /opt/studio/bin/studio\n",
+	}, nil)
+	p := NewProber(filepath.Join(root, "proc"))
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, "proc/1300", name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if pids, _ := p.find(); len(pids) != 0 {
+		t.Fatalf("find() = %v, want none", pids)
+	}
+
+	// Same process: the cached result stands
+	write("maps", libobsMaps)
+	if pids, _ := p.find(); len(pids) != 0 {
+		t.Errorf("find() = %v, want the cached result", pids)
+	}
+
+	// A new process with the same PID is checked again
+	write("stat", strings.Replace(stat("1300", "studio"), " 500 ", " 900 ", 1))
+	if pids, _ := p.find(); len(pids) != 1 {
+		t.Errorf("find() = %v, want [1300] after the PID was reused", pids)
+	}
+
+	// Once a process matches by name, maps aren't read at all
+	if err := os.Mkdir(filepath.Join(root, "proc/1200"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "proc/1200/comm"), []byte("obs\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p = NewProber(filepath.Join(root, "proc"))
+	if pids, _ := p.find(); len(pids) != 1 || pids[0] != 1200 {
+		t.Errorf("find() = %v, want [1200]", pids)
+	}
+	if p.libobs != nil {
+		t.Errorf("maps were checked although OBS matched by name: %v", p.libobs)
+	}
+}
+
+func TestProbe(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	root := testutil.FakeTree(t, map[string]string{
+		"proc/stat":         "cpu  1 2 3 4\nbtime 1760000000\n",
+		"proc/4242/comm":    "obs\n",
+		"proc/4242/stat":    stat("4242", "obs"),
+		"proc/4242/cmdline": "/app/bin/obs\x00--profile\x00Twitch\x00--scene=Starting\x00--minimize-to-tray\x00",
+		"proc/4242/cgroup":  "0::/user.slice/user-1000.slice/user@1000.service/app.slice/app-flatpak-com.obsproject.Studio-4242.scope\n",
+	}, nil)
+
+	status, err := NewProber(filepath.Join(root, "proc")).Probe()
+	if err != nil {
+		t.Fatalf("Probe() error = %v", err)
+	}
+	if !status.Running || status.Process == nil {
+		t.Fatalf("Probe() = %+v, want a running OBS", status)
+	}
+
+	proc := status.Process
+	got := [...]any{proc.PID, proc.Name, proc.InstallType, proc.Threads, proc.RSSBytes, proc.Profile, proc.Scene, proc.MinimizeToTray, proc.StartedAt.Unix()}
+	want := [...]any{4242, "obs", InstallFlatpak, 30, 1000 * uint64(os.Getpagesize()), "Twitch", "Starting", true, int64(1760000005)}
+	if got != want {
+		t.Errorf("Process = %v, want %v", got, want)
+	}
+}
+
+func TestProbeNotRunning(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	status, err := NewProber(t.TempDir()).Probe()
+	if err != nil {
+		t.Fatalf("Probe() error = %v", err)
+	}
+	if status.Running {
+		t.Errorf("Running = true, want false")
+	}
+
+	if _, err := NewProber(filepath.Join(t.TempDir(), "missing")).Probe(); err == nil {
+		t.Error("Probe() error = nil for an unreadable process list")
+	}
+}
@@ -0,0 +1,180 @@
+package obs
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// WebSocketConfig is the obs-websocket server configuration of the OBS
+// user. The password never leaves the agent through Status.
+type WebSocketConfig struct {
+	Enabled      bool   `json:"enabled"`
+	Port         int    `json:"port"`
+	AuthRequired bool   `json:"auth_required"`
+	ConfigPath   string `json:"config_path"`
+	Password     string `json:"-"`
+}
+
+// ErrNoWebSocketConfig means no obs-websocket configuration was found.
+var ErrNoWebSocketConfig = errors.New("obs-websocket config not found")
+
+// Config directories relative to the user's home, per install type.
+var configDirs = map[string]string{
+	InstallNative:  ".config/obs-studio",
+	InstallFlatpak: ".var/app/com.obsproject.Studio/config/obs-studio",
+	InstallSnap:    "snap/obs-studio/current/.config/obs-studio",
+}
+
+// DiscoverWebSocket reads the obs-websocket configuration, including the
+// password. It looks in the home of the running OBS first, then in $HOME.
+func DiscoverWebSocket() (*WebSocketConfig, error) {
+	return defaultProber.DiscoverWebSocket()
+}
+
+func (p *Prober) DiscoverWebSocket() (*WebSocketConfig, error) {
+	var proc *Process
+	if pids, _ := p.find(); len(pids) > 0 {
+		// Only what webSocketConfig needs, a full inspect would skew the CPU sample
+		proc = &Process{PID: pids[0], InstallType: p.installType(pids[0])}
+	}
+	return p.webSocketConfig(proc)
+}
+
+func (p *Prober) webSocketConfig(proc *Process) (*WebSocketConfig, error) {
+	var homes []string
+	installType := InstallNative
+
+	if proc != nil {
+		installType = proc.InstallType
+		if home := p.processHome(proc.PID); home != "" {
+			homes = append(homes, home)
+		}
+	}
+	if home := os.Getenv("HOME"); home != "" {
+		homes = append(homes, home)
+	}
+
+	// The install type of the running OBS decides which directory is live,
+	// the others may hold stale configs from earlier installs.
+	order := []string{installType, InstallNative, InstallFlatpak, InstallSnap}
+
+	for _, home := range homes {
+		for _, typ := range order {
+			dir, ok := configDirs[typ]
+			if !ok {
+				continue
+			}
+			dir = filepath.Join(home, dir)
+
+			if cfg, err := readWebSocketJSON(filepath.Join(dir, "plugin_config", "obs-websocket", "config.json")); err == nil {
+				return cfg, nil
+			}
+			// OBS 28 and 29 kept the settings in global.ini
+			if cfg, err := readWebSocketINI(filepath.Join(dir, "global.ini")); err == nil {
+				return cfg, nil
+			}
+		}
+	}
+
+	return nil, ErrNoWebSocketConfig
+}
+
+// processHome returns the home directory of the user running pid.
+func (p *Prober) processHome(pid int) string {
+	data, err := os.ReadFile(filepath.Join(p.ProcRoot, strconv.Itoa(pid), "status"))
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "Uid:" {
+			continue
+		}
+		u, err := user.LookupId(fields[1])
+		if err != nil {
+			return ""
+		}
+		return u.HomeDir
+	}
+	return ""
+}
+
+func readWebSocketJSON(path string) (*WebSocketConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw struct {
+		ServerEnabled  bool   `json:"server_enabled"`
+		ServerPort     int    `json:"server_port"`
+		AuthRequired   bool   `json:"auth_required"`
+		ServerPassword string `json:"server_password"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	return &WebSocketConfig{
+		Enabled:      raw.ServerEnabled,
+		Port:         raw.ServerPort,
+		AuthRequired: raw.AuthRequired,
+		Password:     raw.ServerPassword,
+		ConfigPath:   path,
+	}, nil
+}
+
+// readWebSocketINI reads the [OBSWebSocket] section of global.ini.
+func readWebSocketINI(path string) (*WebSocketConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cfg := &WebSocketConfig{ConfigPath: path}
+	found := false
+	inSection := false
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if strings.HasPrefix(line, "[") {
+			inSection = line == "[OBSWebSocket]"
+			continue
+		}
+		if !inSection {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		found = true
+
+		switch key {
+		case "ServerEnabled":
+			cfg.Enabled = value == "true"
+		case "ServerPort":
+			cfg.Port, _ = strconv.Atoi(value)
+		case "AuthRequired":
+			cfg.AuthRequired = value == "true"
+		case "ServerPassword":
+			cfg.Password = value
+		}
+	}
+
+	if !found {
+		return nil, ErrNoWebSocketConfig
+	}
+	return cfg, nil
+}
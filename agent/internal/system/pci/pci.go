@@ -0,0 +1,100 @@
+// Package pci identifies PCI devices through sysfs and the pci.ids database.
+package pci
+
+import (
+	_ "embed"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"kit.workmate/live-agent/internal/system/hwids"
+)
+
+// Device is a PCI function with its resolved names.
+type Device struct {
+	Slot     string `json:"slot"`
+	VendorID string `json:"vendor_id"`
+	DeviceID string `json:"device_id"`
+	Class    string `json:"class,omitempty"`
+	Vendor   string `json:"vendor,omitempty"`
+	Model    string `json:"model,omitempty"`
+	Driver   string `json:"driver,omitempty"`
+}
+
+// DatabasePaths are the usual locations of the system pci.ids.
+var DatabasePaths = []string{
+	"/usr/share/hwdata/pci.ids",
+	"/usr/share/misc/pci.ids",
+	"/usr/share/pci.ids",
+	"/usr/share/misc/pci.ids.gz",
+}
+
+//go:embed pci.ids
+var fallbackIDs []byte
+
+var (
+	dbOnce sync.Once
+	db     *hwids.Database
+)
+
+// Database returns the pci.ids database, loading it on first use.
+func Database() *hwids.Database {
+	dbOnce.Do(func() {
+		db = hwids.Load(DatabasePaths, fallbackIDs)
+	})
+	return db
+}
+
+// FromSysfs reads a PCI device from its sysfs directory, e.g.
+// /sys/bus/pci/devices/0000:01:00.0 or a symlink pointing there.
+// It returns false if the directory is not a PCI device.
+func FromSysfs(dir string) (Device, bool) {
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return Device{}, false
+	}
+
+	if linkName(filepath.Join(resolved, "subsystem")) != "pci" {
+		return Device{}, false
+	}
+
+	dev := Device{
+		Slot:     filepath.Base(resolved),
+		VendorID: hexID(readString(filepath.Join(resolved, "vendor"))),
+		DeviceID: hexID(readString(filepath.Join(resolved, "device"))),
+		Class:    readString(filepath.Join(resolved, "class")),
+		Driver:   linkName(filepath.Join(resolved, "driver")),
+	}
+
+	if dev.VendorID == "" {
+		return Device{}, false
+	}
+
+	ids := Database()
+	dev.Vendor = ids.Vendor(dev.VendorID)
+	dev.Model = ids.Device(dev.VendorID, dev.DeviceID)
+
+	return dev, true
+}
+
+// hexID turns sysfs' "0x10de" into "10de".
+func hexID(s string) string {
+	return strings.TrimPrefix(strings.ToLower(s), "0x")
+}
+
+func readString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func linkName(path string) string {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(target)
+}
@@ -0,0 +1,254 @@
+package usb
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Prober reads USB devices below Root, so tests can point it at a fake tree.
+type Prober struct {
+	Root string
+}
+
+func NewProber(root string) *Prober {
+	return &Prober{Root: root}
+}
+
+func Probe() Status {
+	return NewProber("/").Probe()
+}
+
+func (p *Prober) Probe() Status {
+	dir := p.path("/sys/bus/usb/devices")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return Status{Devices: []Device{}}
+	}
+
+	devices := []Device{}
+	for _, e := range entries {
+		name := e.Name()
+		// Interfaces are "1-2:1.0", root hubs "usb1"
+		if strings.Contains(name, ":") || strings.HasPrefix(name, "usb") {
+			continue
+		}
+
+		if dev, ok := readDevice(filepath.Join(dir, name)); ok {
+			devices = append(devices, dev)
+		}
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		if devices[i].Bus != devices[j].Bus {
+			return devices[i].Bus < devices[j].Bus
+		}
+		return devices[i].Path < devices[j].Path
+	})
+
+	return Status{Devices: devices}
+}
+
+func (p *Prober) path(path string) string {
+	return filepath.Join(p.Root, path)
+}
+
+// FromSysfs finds the USB device a sysfs device belongs to, e.g. the
+// camera behind /sys/class/video4linux/video0/device, which is one of
+// its interfaces. It returns false for devices not on USB.
+func FromSysfs(dir string) (Device, bool) {
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return Device{}, false
+	}
+
+	for ; resolved != "/" && resolved != "."; resolved = filepath.Dir(resolved) {
+		name := filepath.Base(resolved)
+		if strings.HasPrefix(name, "usb") {
+			return Device{}, false // reached the root hub
+		}
+		if _, err := os.Stat(filepath.Join(resolved, "idVendor")); err == nil {
+			return readDevice(resolved)
+		}
+	}
+	return Device{}, false
+}
+
+func readDevice(dir string) (Device, bool) {
+	dev := Device{
+		Path:         filepath.Base(dir),
+		VendorID:     strings.ToLower(readString(filepath.Join(dir, "idVendor"))),
+		ProductID:    strings.ToLower(readString(filepath.Join(dir, "idProduct"))),
+		Manufacturer: readString(filepath.Join(dir, "manufacturer")),
+		Product:      readString(filepath.Join(dir, "product")),
+		Serial:       readString(filepath.Join(dir, "serial")),
+		Version:      readString(filepath.Join(dir, "version")),
+		Classes:      []string{},
+		Drivers:      []string{},
+	}
+	if dev.VendorID == "" {
+		return Device{}, false
+	}
+
+	dev.Bus, _ = strconv.Atoi(readString(filepath.Join(dir, "busnum")))
+	dev.Device, _ = strconv.Atoi(readString(filepath.Join(dir, "devnum")))
+
+	ids := Database()
+	dev.Vendor = ids.Vendor(dev.VendorID)
+	dev.Model = ids.Device(dev.VendorID, dev.ProductID)
+
+	dev.SpeedMbps, _ = strconv.ParseFloat(readString(filepath.Join(dir, "speed")), 64)
+	dev.Speed = speedName(dev.SpeedMbps)
+
+	// A SuperSpeed device on a USB 2 link reports bcdUSB 2.10, only its
+	// BOS descriptor still announces SuperSpeed
+	if bos, err := os.ReadFile(filepath.Join(dir, "bos_descriptors")); err == nil {
+		dev.MaxSpeedMbps = bosMaxSpeed(bos)
+	} else if version, _ := strconv.ParseFloat(dev.Version, 64); version >= 3 {
+		dev.MaxSpeedMbps = 5000
+	}
+	dev.Degraded = dev.SpeedMbps > 0 && dev.SpeedMbps < dev.MaxSpeedMbps
+
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dev.PortSpeedMbps = portSpeed(resolved)
+	}
+
+	// bMaxPower is e.g. "500mA", bit 6 of bmAttributes means self-powered
+	dev.MaxPowerMA, _ = strconv.Atoi(strings.TrimSuffix(readString(filepath.Join(dir, "bMaxPower")), "mA"))
+	if attrs, err := strconv.ParseUint(readString(filepath.Join(dir, "bmAttributes")), 16, 8); err == nil {
+		dev.SelfPowered = attrs&0x40 != 0
+	}
+
+	// Class 00 means each interface declares its own
+	addClass(&dev, readString(filepath.Join(dir, "bDeviceClass")))
+
+	interfaces, _ := filepath.Glob(filepath.Join(dir, dev.Path+":*"))
+	for _, iface := range interfaces {
+		addClass(&dev, readString(filepath.Join(iface, "bInterfaceClass")))
+
+		driver := linkName(filepath.Join(iface, "driver"))
+		if driver != "" && !slices.Contains(dev.Drivers, driver) {
+			dev.Drivers = append(dev.Drivers, driver)
+		}
+	}
+
+	return dev, true
+}
+
+// BOS descriptor and device capability types, see USB 3.2 section 9.6.2
+const (
+	descBOS           = 0x0f
+	descDeviceCap     = 0x10
+	capSuperSpeed     = 0x03
+	capSuperSpeedPlus = 0x0a
+)
+
+// bosMaxSpeed returns the fastest SuperSpeed rate in Mbps a BOS
+// descriptor announces, 0 if it announces none.
+func bosMaxSpeed(data []byte) float64 {
+	if len(data) < 5 || data[1] != descBOS {
+		return 0
+	}
+	if total := int(binary.LittleEndian.Uint16(data[2:4])); total < len(data) {
+		data = data[:total]
+	}
+
+	var mbps float64
+	for i := int(data[0]); i+3 <= len(data); {
+		length := int(data[i])
+		if length < 3 || i+length > len(data) {
+			break
+		}
+		if data[i+1] == descDeviceCap {
+			switch data[i+2] {
+			case capSuperSpeed:
+				mbps = max(mbps, 5000)
+			case capSuperSpeedPlus:
+				mbps = max(mbps, superSpeedPlusRate(data[i:i+length]))
+			}
+		}
+		i += length
+	}
+	return mbps
+}
+
+// superSpeedPlusRate returns the fastest sublink speed of a SuperSpeedPlus
+// capability in Mbps, 10000 if it lists none. Lane counts are not
+// announced here, so a Gen 2x2 device counts as 10000.
+func superSpeedPlusRate(desc []byte) float64 {
+	var mbps float64
+	if len(desc) >= 12 {
+		// bmAttributes bits 0-4 are the sublink speed attribute count - 1
+		count := int(binary.LittleEndian.Uint32(desc[4:8])&0x1f) + 1
+		for i := 0; i < count && 12+4*i+4 <= len(desc); i++ {
+			attr := binary.LittleEndian.Uint32(desc[12+4*i:])
+			mantissa := float64(attr >> 16)
+			switch (attr >> 4) & 0x3 { // lane speed exponent
+			case 1:
+				mantissa /= 1000 // Kb/s
+			case 2:
+				// Mb/s
+			case 3:
+				mantissa *= 1000 // Gb/s
+			default:
+				mantissa /= 1e6 // b/s
+			}
+			mbps = max(mbps, mantissa)
+		}
+	}
+	if mbps == 0 {
+		mbps = 10000
+	}
+	return mbps
+}
+
+// portSpeed returns the fastest speed the port of a resolved device
+// directory supports: its hub's speed, or that of the SuperSpeed peer
+// the kernel links each USB 2 port of a USB 3 hub or root hub to.
+func portSpeed(dir string) float64 {
+	hub := filepath.Dir(dir)
+	mbps, _ := strconv.ParseFloat(readString(filepath.Join(hub, "speed")), 64)
+
+	// "3-1.4" is port 4 of hub "3-1", whose ports are "3-1:1.0/3-1-port4";
+	// root hub ports are "usb3/3-0:1.0/usb3-port1"
+	name := filepath.Base(dir)
+	port := name[strings.LastIndexAny(name, "-.")+1:]
+	links, _ := filepath.Glob(filepath.Join(hub, "*:1.0", "*-port"+port, "peer"))
+	for _, link := range links {
+		peer, err := filepath.EvalSymlinks(link)
+		if err != nil {
+			continue
+		}
+		peerHub := filepath.Dir(filepath.Dir(peer))
+		peerMbps, _ := strconv.ParseFloat(readString(filepath.Join(peerHub, "speed")), 64)
+		mbps = max(mbps, peerMbps)
+	}
+	return mbps
+}
+
+func addClass(dev *Device, code string) {
+	name, ok := classNames[strings.ToLower(code)]
+	if ok && !slices.Contains(dev.Classes, name) {
+		dev.Classes = append(dev.Classes, name)
+	}
+}
+
+func readString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func linkName(path string) string {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(target)
+}
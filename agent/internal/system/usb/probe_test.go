@@ -0,0 +1,127 @@
+package usb
+
+import (
+	"testing"
+
+	"kit.workmate/live-agent/internal/testutil"
+)
+
+var (
+	// BOS with a USB 2.0 extension and a SuperSpeed capability
+	bosSuperSpeed = []byte{
+		0x05, 0x0f, 0x16, 0x00, 0x02,
+		0x07, 0x10, 0x02, 0x06, 0x00, 0x00, 0x00,
+		0x0a, 0x10, 0x03, 0x00, 0x0e, 0x00, 0x01, 0x0a, 0xff, 0x07,
+	}
+	// BOS with a SuperSpeedPlus capability for Gen 2 (10 Gb/s), RX and TX
+	bosSuperSpeedPlus = []byte{
+		0x05, 0x0f, 0x19, 0x00, 0x01,
+		0x14, 0x10, 0x0a, 0x00,
+		0x01, 0x00, 0x00, 0x00,
+		0x00, 0x11, 0x00, 0x00,
+		0x30, 0x40, 0x0a, 0x00,
+		0xb0, 0x40, 0x0a, 0x00,
+	}
+	// BOS with only a USB 2.0 extension
+	bosUSB2 = []byte{
+		0x05, 0x0f, 0x0c, 0x00, 0x01,
+		0x07, 0x10, 0x02, 0x06, 0x00, 0x00, 0x00,
+	}
+)
+
+func TestBOSMaxSpeed(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want float64
+	}{
+		{"superspeed", bosSuperSpeed, 5000},
+		{"superspeed plus", bosSuperSpeedPlus, 10000},
+		{"usb 2 only", bosUSB2, 0},
+		{"empty", nil, 0},
+		{"not a bos", []byte{0x12, 0x01, 0x00, 0x02, 0x00}, 0},
+		{"truncated capability", bosSuperSpeed[:15], 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bosMaxSpeed(tt.data); got != tt.want {
+				t.Errorf("bosMaxSpeed() = %g, want %g", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeSpeeds(t *testing.T) {
+	// An xHCI controller with a USB 2 bus (usb1) and a USB 3 bus (usb2),
+	// whose second ports are peers
+	const (
+		usb1 = "/sys/devices/pci0000:00/0000:00:14.0/usb1"
+		usb2 = "/sys/devices/pci0000:00/0000:00:14.0/usb2"
+	)
+
+	device := func(dir, speed, version string) map[string]string {
+		return map[string]string{
+			dir + "/idVendor":  "0fd9\n",
+			dir + "/idProduct": "0066\n",
+			dir + "/speed":     speed + "\n",
+			dir + "/version":   " " + version + "\n",
+		}
+	}
+	files := map[string]string{
+		usb1 + "/speed":                      "480\n",
+		usb1 + "/1-0:1.0/usb1-port2/connect": "",
+		usb1 + "/1-0:1.0/usb1-port3/connect": "",
+		usb2 + "/speed":                      "5000\n",
+		usb2 + "/2-0:1.0/usb2-port2/connect": "",
+		usb2 + "/2-0:1.0/usb2-port3/connect": "",
+	}
+	// A SuperSpeed camera on the USB 2 half of a USB 3 port, e.g. over a
+	// USB 2 cable, a keyboard on a USB 2 only port and a disk on USB 3
+	for _, dev := range []map[string]string{
+		device(usb1+"/1-2", "480", "2.10"),
+		device(usb1+"/1-3", "12", "2.00"),
+		device(usb2+"/2-2", "5000", "3.20"),
+	} {
+		for path, content := range dev {
+			files[path] = content
+		}
+	}
+	files[usb1+"/1-2/bos_descriptors"] = string(bosSuperSpeed)
+	files[usb2+"/2-2/bos_descriptors"] = string(bosSuperSpeed)
+
+	root := testutil.FakeTree(t, files, map[string]string{
+		usb1 + "/1-0:1.0/usb1-port2/peer": usb2 + "/2-0:1.0/usb2-port2",
+		usb2 + "/2-0:1.0/usb2-port2/peer": usb1 + "/1-0:1.0/usb1-port2",
+		"/sys/bus/usb/devices/usb1":       usb1,
+		"/sys/bus/usb/devices/usb2":       usb2,
+		"/sys/bus/usb/devices/1-2":        usb1 + "/1-2",
+		"/sys/bus/usb/devices/1-3":        usb1 + "/1-3",
+		"/sys/bus/usb/devices/2-2":        usb2 + "/2-2",
+	})
+
+	status := NewProber(root).Probe()
+
+	want := map[string]struct {
+		maxSpeed, portSpeed float64
+		degraded            bool
+	}{
+		"1-2": {5000, 5000, true},
+		"1-3": {0, 480, false},
+		"2-2": {5000, 5000, false},
+	}
+	if len(status.Devices) != len(want) {
+		t.Fatalf("got %d devices, want %d", len(status.Devices), len(want))
+	}
+	for _, dev := range status.Devices {
+		w, ok := want[dev.Path]
+		if !ok {
+			t.Errorf("unexpected device %s", dev.Path)
+			continue
+		}
+		if dev.MaxSpeedMbps != w.maxSpeed || dev.PortSpeedMbps != w.portSpeed || dev.Degraded != w.degraded {
+			t.Errorf("%s: max %g, port %g, degraded %v; want max %g, port %g, degraded %v",
+				dev.Path, dev.MaxSpeedMbps, dev.PortSpeedMbps, dev.Degraded, w.maxSpeed, w.portSpeed, w.degraded)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Package usb lists USB devices through sysfs and the usb.ids database.
+package usb
+
+import (
+	_ "embed"
+	"sync"
+
+	"kit.workmate/live-agent/internal/system/hwids"
+)
+
+type Status struct {
+	Devices []Device `json:"devices"`
+}
+
+// Device is a USB device below a root hub. IDs are lowercase hex.
+type Device struct {
+	// Path is the sysfs name, bus and port chain, e.g. "3-1.4"
+	Path      string `json:"path"`
+	Bus       int    `json:"bus"`
+	Device    int    `json:"device"`
+	VendorID  string `json:"vendor_id"`
+	ProductID string `json:"product_id"`
+
+	// Vendor and Model come from usb.ids, Manufacturer and Product
+	// from the device's own string descriptors
+	Vendor       string `json:"vendor,omitempty"`
+	Model        string `json:"model,omitempty"`
+	Manufacturer string `json:"manufacturer,omitempty"`
+	Product      string `json:"product,omitempty"`
+	Serial       string `json:"serial,omitempty"`
+
+	// Version is the device's bcdUSB, e.g. "3.20". SuperSpeed devices
+	// report 2.10 when they are connected over USB 2.
+	Version string `json:"version"`
+	// SpeedMbps is the negotiated speed: 1.5, 12, 480, 5000, ...
+	SpeedMbps float64 `json:"speed_mbps"`
+	Speed     string  `json:"speed"`
+	// MaxSpeedMbps is the SuperSpeed rate the device supports, from its
+	// BOS descriptor; 0 for devices that only do USB 2 or less
+	MaxSpeedMbps float64 `json:"max_speed_mbps,omitempty"`
+	// PortSpeedMbps is the fastest speed the port the device is plugged
+	// into supports, counting the SuperSpeed peer of a USB 3 port
+	PortSpeedMbps float64 `json:"port_speed_mbps,omitempty"`
+	// Degraded is set if the device supports a faster speed than it
+	// got, usually a USB 3 device on a USB 2 port, hub or cable
+	Degraded bool `json:"degraded"`
+
+	MaxPowerMA  int  `json:"max_power_ma"`
+	SelfPowered bool `json:"self_powered"`
+
+	// Classes are the device and interface classes, e.g. video, audio, hid
+	Classes []string `json:"classes"`
+	// Drivers are the kernel drivers bound to the interfaces
+	Drivers []string `json:"drivers"`
+}
+
+// DatabasePaths are the usual locations of the system usb.ids.
+var DatabasePaths = []string{
+	"/usr/share/hwdata/usb.ids",
+	"/usr/share/misc/usb.ids",
+	"/usr/share/usb.ids",
+	"/var/lib/usbutils/usb.ids",
+	"/usr/share/misc/usb.ids.gz",
+}
+
+//go:embed usb.ids
+var fallbackIDs []byte
+
+var (
+	dbOnce sync.Once
+	db     *hwids.Database
+)
+
+// Database returns the usb.ids database, loading it on first use.
+func Database() *hwids.Database {
+	dbOnce.Do(func() {
+		db = hwids.Load(DatabasePaths, fallbackIDs)
+	})
+	return db
+}
+
+// classNames are the USB-IF base classes worth naming.
+var classNames = map[string]string{
+	"01": "audio",
+	"02": "communications",
+	"03": "hid",
+	"06": "image",
+	"07": "printer",
+	"08": "mass-storage",
+	"09": "hub",
+	"0a": "cdc-data",
+	"0b": "smart-card",
+	"0e": "video",
+	"10": "audio-video",
+	"e0": "wireless",
+	"ef": "misc",
+	"fe": "application",
+	"ff": "vendor-specific",
+}
+
+// speedName names the negotiated speed in Mbps as the kernel reports it.
+func speedName(mbps float64) string {
+	switch {
+	case mbps >= 20000:
+		return "super-speed-plus-2x2"
+	case mbps >= 10000:
+		return "super-speed-plus"
+	case mbps >= 5000:
+		return "super-speed"
+	case mbps >= 480:
+		return "high-speed"
+	case mbps >= 12:
+		return "full-speed"
+	case mbps > 0:
+		return "low-speed"
+	}
+	return "unknown"
+}
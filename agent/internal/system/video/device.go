@@ -0,0 +1,234 @@
+package video
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+
+	"kit.workmate/live-agent/internal/system/devid"
+	"kit.workmate/live-agent/internal/system/pci"
+	"kit.workmate/live-agent/internal/system/usb"
+)
+
+// sysClassV4L ist das sysfs-Verzeichnis der V4L2-Geräte.
+var sysClassV4L = "/sys/class/video4linux"
+
+// devV4L enthält die udev-Links by-id und by-path auf die Geräteknoten.
+var devV4L = "/dev/v4l"
+
+// Device beschreibt ein V4L2-Gerät mit allem, was es laut Treiber kann.
+type Device struct {
+	Path     string   `json:"path"`
+	Driver   string   `json:"driver,omitempty"`
+	Card     string   `json:"card,omitempty"`
+	BusInfo  string   `json:"bus_info,omitempty"`
+	Capture  bool     `json:"capture"`
+	Metadata bool     `json:"metadata"`
+	Formats  []Format `json:"formats,omitempty"`
+	Error    string   `json:"error,omitempty"`
+
+	// Identity bleibt über Neustarts und Umstecken gleich, anders als
+	// die Nummer in Path
+	Identity devid.Identity `json:"identity"`
+
+	// PCI ist nur bei PCIe-Karten gesetzt, USB nur bei USB-Geräten
+	PCI *pci.Device `json:"pci,omitempty"`
+	USB *usb.Device `json:"usb,omitempty"`
+}
+
+// Format ist ein Pixelformat (z.B. "YUYV", "MJPG") mit seinen Auflösungen.
+type Format struct {
+	FourCC      string      `json:"fourcc"`
+	Description string      `json:"description"`
+	Compressed  bool        `json:"compressed"`
+	Sizes       []FrameSize `json:"sizes,omitempty"`
+}
+
+// FrameSize ist eine Auflösung mit den dafür unterstützten Bildraten.
+// Bei stufenlosen Geräten (stepwise/continuous) wird nur das Maximum gemeldet.
+type FrameSize struct {
+	Width  uint32    `json:"width"`
+	Height uint32    `json:"height"`
+	FPS    []float64 `json:"fps,omitempty"`
+}
+
+// Supports prüft, ob das Gerät die Auflösung mit mindestens minFPS Bildern
+// pro Sekunde liefern kann, egal in welchem Pixelformat.
+func (d Device) Supports(width, height uint32, minFPS float64) bool {
+	for _, f := range d.Formats {
+		for _, s := range f.Sizes {
+			if s.Width != width || s.Height != height {
+				continue
+			}
+			for _, rate := range s.FPS {
+				if rate >= minFPS {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Capability ist das Ergebnis von VIDIOC_QUERYCAP.
+type Capability struct {
+	Driver       string
+	Card         string
+	BusInfo      string
+	Capabilities uint32
+}
+
+// FormatDesc ist das Ergebnis von VIDIOC_ENUM_FMT.
+type FormatDesc struct {
+	PixelFormat uint32
+	Description string
+	Flags       uint32
+}
+
+// Handle kapselt die V4L2-ioctls eines geöffneten Geräts.
+// So lässt sich Inspect ohne echte Hardware mit Fixtures füttern.
+type Handle interface {
+	QueryCap() (Capability, error)
+	EnumFormats(bufType uint32) ([]FormatDesc, error)
+	EnumFrameSizes(pixelFormat uint32) ([]FrameSize, error)
+	EnumFrameIntervals(pixelFormat, width, height uint32) ([]float64, error)
+	Close() error
+}
+
+// Opener öffnet ein Gerät für die Abfrage.
+type Opener func(path string) (Handle, error)
+
+// V4L2-Konstanten aus linux/videodev2.h
+const (
+	capVideoCapture      = 0x00000001
+	capVideoCaptureMPlan = 0x00001000
+	capMetaCapture       = 0x00800000
+
+	bufTypeVideoCapture      = 1
+	bufTypeVideoCaptureMPlan = 9
+
+	fmtFlagCompressed = 0x0001
+)
+
+// Inspect fragt alle übergebenen Geräte ab. Fehler pro Gerät landen im
+// Error-Feld, damit ein kaputtes Gerät die anderen nicht versteckt.
+func Inspect(paths []string, open Opener) []Device {
+	devices := make([]Device, 0, len(paths))
+
+	for _, path := range paths {
+		dev, err := InspectDevice(path, open)
+		if err != nil {
+			dev.Error = err.Error()
+		}
+		sysfs := filepath.Join(sysClassV4L, filepath.Base(path), "device")
+		if info, ok := pci.FromSysfs(sysfs); ok {
+			dev.PCI = &info
+		}
+		if info, ok := usb.FromSysfs(sysfs); ok {
+			dev.USB = &info
+		}
+
+		dev.Identity = devid.Resolve(devV4L, path)
+		if dev.USB != nil {
+			dev.Identity.Serial = dev.USB.Serial
+			dev.Identity.USB = dev.USB.VendorID + ":" + dev.USB.ProductID
+		}
+		devices = append(devices, dev)
+	}
+
+	return devices
+}
+
+// InspectDevice liest Treiberinfos, Formate, Auflösungen und Bildraten eines Geräts.
+func InspectDevice(path string, open Opener) (Device, error) {
+	dev := Device{Path: path}
+
+	h, err := open(path)
+	if err != nil {
+		return dev, fmt.Errorf("open: %w", err)
+	}
+	defer h.Close()
+
+	c, err := h.QueryCap()
+	if err != nil {
+		return dev, fmt.Errorf("querycap: %w", err)
+	}
+
+	dev.Driver = c.Driver
+	dev.Card = c.Card
+	dev.BusInfo = c.BusInfo
+	dev.Capture = c.Capabilities&(capVideoCapture|capVideoCaptureMPlan) != 0
+	dev.Metadata = c.Capabilities&capMetaCapture != 0
+
+	if !dev.Capture {
+		// Metadaten-Knoten (z.B. das zweite /dev/videoN einer UVC-Kamera)
+		// haben keine Bildformate.
+		return dev, nil
+	}
+
+	bufType := uint32(bufTypeVideoCapture)
+	if c.Capabilities&capVideoCapture == 0 {
+		bufType = bufTypeVideoCaptureMPlan
+	}
+
+	descs, err := h.EnumFormats(bufType)
+	if err != nil {
+		return dev, fmt.Errorf("enum formats: %w", err)
+	}
+
+	for _, desc := range descs {
+		format := Format{
+			FourCC:      FourCC(desc.PixelFormat),
+			Description: desc.Description,
+			Compressed:  desc.Flags&fmtFlagCompressed != 0,
+		}
+
+		sizes, err := h.EnumFrameSizes(desc.PixelFormat)
+		if err != nil {
+			// Manche Treiber kennen ENUM_FRAMESIZES nicht, das Format gibt es trotzdem.
+			dev.Formats = append(dev.Formats, format)
+			continue
+		}
+
+		for _, size := range sizes {
+			size.FPS, _ = h.EnumFrameIntervals(desc.PixelFormat, size.Width, size.Height)
+			sort.Sort(sort.Reverse(sort.Float64Slice(size.FPS)))
+			format.Sizes = append(format.Sizes, size)
+		}
+
+		sort.Slice(format.Sizes, func(i, j int) bool {
+			a, b := format.Sizes[i], format.Sizes[j]
+			if a.Width != b.Width {
+				return a.Width > b.Width
+			}
+			return a.Height > b.Height
+		})
+
+		dev.Formats = append(dev.Formats, format)
+	}
+
+	return dev, nil
+}
+
+// FourCC wandelt einen V4L2-Pixelformatcode in seine lesbare Form um.
+func FourCC(code uint32) string {
+	b := []byte{
+		byte(code),
+		byte(code >> 8),
+		byte(code >> 16),
+		byte(code >> 24),
+	}
+	for len(b) > 0 && (b[len(b)-1] == ' ' || b[len(b)-1] == 0) {
+		b = b[:len(b)-1]
+	}
+	return string(b)
+}
+
+// fps rechnet ein V4L2-Frameintervall (Sekunden als Bruch) in Bilder pro Sekunde um.
+func fps(numerator, denominator uint32) float64 {
+	if numerator == 0 {
+		return 0
+	}
+	return math.Round(float64(denominator)/float64(numerator)*100) / 100
+}
@@ -0,0 +1,230 @@
+//go:build linux
+
+package video
+
+import (
+	"bytes"
+	"errors"
+	"syscall"
+	"unsafe"
+)
+
+// ioctl-Nummern aus linux/videodev2.h (_IOR/_IOWR mit Typ 'V')
+const (
+	vidiocQueryCap           = 0x80685600
+	vidiocEnumFmt            = 0xc0405602
+	vidiocEnumFrameSizes     = 0xc02c564a
+	vidiocEnumFrameIntervals = 0xc034564b
+	capDeviceCaps            = 0x80000000
+	frmTypeDiscrete          = 1
+	frmTypeContinuous        = 2
+	frmTypeStepwise          = 3
+	maxEnumEntries           = 256
+)
+
+type v4l2Capability struct {
+	Driver       [16]byte
+	Card         [32]byte
+	BusInfo      [32]byte
+	Version      uint32
+	Capabilities uint32
+	DeviceCaps   uint32
+	Reserved     [3]uint32
+}
+
+type v4l2FmtDesc struct {
+	Index       uint32
+	Type        uint32
+	Flags       uint32
+	Description [32]byte
+	PixelFormat uint32
+	MbusCode    uint32
+	Reserved    [3]uint32
+}
+
+type v4l2FrmSizeEnum struct {
+	Index       uint32
+	PixelFormat uint32
+	Type        uint32
+	// discrete: width, height
+	// stepwise: min_width, max_width, step_width, min_height, max_height, step_height
+	Union    [6]uint32
+	Reserved [2]uint32
+}
+
+type v4l2FrmIvalEnum struct {
+	Index       uint32
+	PixelFormat uint32
+	Width       uint32
+	Height      uint32
+	Type        uint32
+	// discrete: numerator, denominator
+	// stepwise: min, max, step (jeweils numerator, denominator)
+	Union    [6]uint32
+	Reserved [2]uint32
+}
+
+// v4l2Handle ist ein per open(2) geöffnetes V4L2-Gerät.
+type v4l2Handle struct {
+	fd int
+}
+
+// OpenDevice öffnet ein Gerät nicht-blockierend und nur zum Abfragen.
+// Ein laufender Stream (z.B. in OBS) wird dadurch nicht gestört.
+func OpenDevice(path string) (Handle, error) {
+	fd, err := syscall.Open(path, syscall.O_RDWR|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &v4l2Handle{fd: fd}, nil
+}
+
+func (h *v4l2Handle) ioctl(req uintptr, arg unsafe.Pointer) error {
+	for {
+		_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(h.fd), req, uintptr(arg))
+		switch errno {
+		case 0:
+			return nil
+		case syscall.EINTR:
+			continue
+		default:
+			return errno
+		}
+	}
+}
+
+func (h *v4l2Handle) QueryCap() (Capability, error) {
+	var raw v4l2Capability
+	if err := h.ioctl(vidiocQueryCap, unsafe.Pointer(&raw)); err != nil {
+		return Capability{}, err
+	}
+
+	return decodeCap(raw), nil
+}
+
+func decodeCap(raw v4l2Capability) Capability {
+	// device_caps beschreibt genau diesen Knoten, capabilities das ganze Gerät
+	caps := raw.Capabilities
+	if caps&capDeviceCaps != 0 {
+		caps = raw.DeviceCaps
+	}
+
+	return Capability{
+		Driver:       cString(raw.Driver[:]),
+		Card:         cString(raw.Card[:]),
+		BusInfo:      cString(raw.BusInfo[:]),
+		Capabilities: caps,
+	}
+}
+
+func (h *v4l2Handle) EnumFormats(bufType uint32) ([]FormatDesc, error) {
+	var descs []FormatDesc
+
+	for i := uint32(0); i < maxEnumEntries; i++ {
+		raw := v4l2FmtDesc{Index: i, Type: bufType}
+		if err := h.ioctl(vidiocEnumFmt, unsafe.Pointer(&raw)); err != nil {
+			if errors.Is(err, syscall.EINVAL) {
+				break // Ende der Liste
+			}
+			return descs, err
+		}
+
+		descs = append(descs, decodeFmtDesc(raw))
+	}
+
+	return descs, nil
+}
+
+func decodeFmtDesc(raw v4l2FmtDesc) FormatDesc {
+	return FormatDesc{
+		PixelFormat: raw.PixelFormat,
+		Description: cString(raw.Description[:]),
+		Flags:       raw.Flags,
+	}
+}
+
+func (h *v4l2Handle) EnumFrameSizes(pixelFormat uint32) ([]FrameSize, error) {
+	var entries []v4l2FrmSizeEnum
+
+	for i := uint32(0); i < maxEnumEntries; i++ {
+		raw := v4l2FrmSizeEnum{Index: i, PixelFormat: pixelFormat}
+		if err := h.ioctl(vidiocEnumFrameSizes, unsafe.Pointer(&raw)); err != nil {
+			if errors.Is(err, syscall.EINVAL) && i > 0 {
+				break
+			}
+			return decodeFrameSizes(entries), err
+		}
+
+		entries = append(entries, raw)
+		// stepwise und continuous haben nur einen Eintrag
+		if raw.Type != frmTypeDiscrete {
+			break
+		}
+	}
+
+	return decodeFrameSizes(entries), nil
+}
+
+func decodeFrameSizes(entries []v4l2FrmSizeEnum) []FrameSize {
+	var sizes []FrameSize
+
+	for _, raw := range entries {
+		switch raw.Type {
+		case frmTypeDiscrete:
+			sizes = append(sizes, FrameSize{Width: raw.Union[0], Height: raw.Union[1]})
+		case frmTypeContinuous, frmTypeStepwise:
+			// Nur die größte Auflösung melden, alles andere wäre eine endlose Liste
+			return append(sizes, FrameSize{Width: raw.Union[1], Height: raw.Union[4]})
+		}
+	}
+
+	return sizes
+}
+
+func (h *v4l2Handle) EnumFrameIntervals(pixelFormat, width, height uint32) ([]float64, error) {
+	var entries []v4l2FrmIvalEnum
+
+	for i := uint32(0); i < maxEnumEntries; i++ {
+		raw := v4l2FrmIvalEnum{Index: i, PixelFormat: pixelFormat, Width: width, Height: height}
+		if err := h.ioctl(vidiocEnumFrameIntervals, unsafe.Pointer(&raw)); err != nil {
+			if errors.Is(err, syscall.EINVAL) && i > 0 {
+				break
+			}
+			return decodeFrameIntervals(entries), err
+		}
+
+		entries = append(entries, raw)
+		if raw.Type != frmTypeDiscrete {
+			break
+		}
+	}
+
+	return decodeFrameIntervals(entries), nil
+}
+
+func decodeFrameIntervals(entries []v4l2FrmIvalEnum) []float64 {
+	var rates []float64
+
+	for _, raw := range entries {
+		switch raw.Type {
+		case frmTypeDiscrete:
+			rates = append(rates, fps(raw.Union[0], raw.Union[1]))
+		case frmTypeContinuous, frmTypeStepwise:
+			// Das kleinste Intervall ergibt die höchste Bildrate
+			return append(rates, fps(raw.Union[0], raw.Union[1]))
+		}
+	}
+
+	return rates
+}
+
+func (h *v4l2Handle) Close() error {
+	return syscall.Close(h.fd)
+}
+
+func cString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
@@ -0,0 +1,238 @@
+//go:build linux
+
+package video
+
+import (
+	"fmt"
+	"syscall"
+	"testing"
+)
+
+const capStreaming = 0x04000000
+
+// fakeHandle beantwortet die ioctls mit festen Kernel-Strukturen und
+// dekodiert sie wie v4l2Handle.
+type fakeHandle struct {
+	cap       v4l2Capability
+	formats   []v4l2FmtDesc
+	sizes     map[uint32][]v4l2FrmSizeEnum
+	intervals map[[3]uint32][]v4l2FrmIvalEnum
+}
+
+func (f *fakeHandle) QueryCap() (Capability, error) {
+	return decodeCap(f.cap), nil
+}
+
+func (f *fakeHandle) EnumFormats(bufType uint32) ([]FormatDesc, error) {
+	var descs []FormatDesc
+	for _, raw := range f.formats {
+		if raw.Type == bufType {
+			descs = append(descs, decodeFmtDesc(raw))
+		}
+	}
+	return descs, nil
+}
+
+func (f *fakeHandle) EnumFrameSizes(pixelFormat uint32) ([]FrameSize, error) {
+	entries, ok := f.sizes[pixelFormat]
+	if !ok {
+		return nil, syscall.EINVAL
+	}
+	return decodeFrameSizes(entries), nil
+}
+
+func (f *fakeHandle) EnumFrameIntervals(pixelFormat, width, height uint32) ([]float64, error) {
+	entries, ok := f.intervals[[3]uint32{pixelFormat, width, height}]
+	if !ok {
+		return nil, syscall.EINVAL
+	}
+	return decodeFrameIntervals(entries), nil
+}
+
+func (f *fakeHandle) Close() error { return nil }
+
+func pixelFormat(fourcc string) uint32 {
+	return uint32(fourcc[0]) | uint32(fourcc[1])<<8 | uint32(fourcc[2])<<16 | uint32(fourcc[3])<<24
+}
+
+func capability(driver, card string, caps, deviceCaps uint32) v4l2Capability {
+	raw := v4l2Capability{Capabilities: caps, DeviceCaps: deviceCaps}
+	copy(raw.Driver[:], driver)
+	copy(raw.Card[:], card)
+	copy(raw.BusInfo[:], "usb-0000:00:14.0-2")
+	return raw
+}
+
+func fmtDesc(bufType uint32, fourcc, description string, flags uint32) v4l2FmtDesc {
+	raw := v4l2FmtDesc{Type: bufType, PixelFormat: pixelFormat(fourcc), Flags: flags}
+	copy(raw.Description[:], description)
+	return raw
+}
+
+func discreteSize(w, h uint32) v4l2FrmSizeEnum {
+	return v4l2FrmSizeEnum{Type: frmTypeDiscrete, Union: [6]uint32{w, h}}
+}
+
+func discreteInterval(num, den uint32) v4l2FrmIvalEnum {
+	return v4l2FrmIvalEnum{Type: frmTypeDiscrete, Union: [6]uint32{num, den}}
+}
+
+var (
+	mjpg = pixelFormat("MJPG")
+	yuyv = pixelFormat("YUYV")
+	nv12 = pixelFormat("NV12")
+
+	// Eine UVC-Kamera meldet in capabilities beide Knoten, in device_caps
+	// nur den jeweiligen
+	uvcCaps = uint32(capVideoCapture | capMetaCapture | capStreaming | capDeviceCaps)
+
+	webcam = &fakeHandle{
+		cap: capability("uvcvideo", "Facecam", uvcCaps, capVideoCapture|capStreaming),
+		formats: []v4l2FmtDesc{
+			fmtDesc(bufTypeVideoCapture, "YUYV", "YUYV 4:2:2", 0),
+			fmtDesc(bufTypeVideoCapture, "MJPG", "Motion-JPEG", fmtFlagCompressed),
+		},
+		sizes: map[uint32][]v4l2FrmSizeEnum{
+			yuyv: {discreteSize(640, 480), discreteSize(1920, 1080)},
+			mjpg: {discreteSize(1280, 720), discreteSize(1920, 1080)},
+		},
+		intervals: map[[3]uint32][]v4l2FrmIvalEnum{
+			{yuyv, 640, 480}:   {discreteInterval(1, 30)},
+			{yuyv, 1920, 1080}: {discreteInterval(1, 5)},
+			{mjpg, 1280, 720}:  {discreteInterval(1, 30), discreteInterval(1, 60)},
+			{mjpg, 1920, 1080}: {discreteInterval(1, 30), discreteInterval(1001, 60000), discreteInterval(1, 60)},
+		},
+	}
+
+	webcamMeta = &fakeHandle{
+		cap: capability("uvcvideo", "Facecam", uvcCaps, capMetaCapture|capStreaming),
+	}
+
+	// Capture-Karte mit Multiplanar-API und stufenlosen Auflösungen
+	captureCard = &fakeHandle{
+		cap: capability("hdmi_rx", "HDMI Capture", capVideoCaptureMPlan|capStreaming, 0),
+		formats: []v4l2FmtDesc{
+			fmtDesc(bufTypeVideoCaptureMPlan, "NV12", "Y/UV 4:2:0", 0),
+		},
+		sizes: map[uint32][]v4l2FrmSizeEnum{
+			nv12: {{Type: frmTypeStepwise, Union: [6]uint32{64, 3840, 2, 64, 2160, 2}}},
+		},
+		intervals: map[[3]uint32][]v4l2FrmIvalEnum{
+			{nv12, 3840, 2160}: {{Type: frmTypeContinuous, Union: [6]uint32{1, 60, 1, 1, 1, 1}}},
+		},
+	}
+
+	// Ohne ENUM_FRAMESIZES gibt es das Format trotzdem
+	legacy = &fakeHandle{
+		cap:     capability("bttv", "BT878 video", capVideoCapture, 0),
+		formats: []v4l2FmtDesc{fmtDesc(bufTypeVideoCapture, "BGR3", "24-bit BGR 8-8-8", 0)},
+	}
+)
+
+func opener(handles map[string]*fakeHandle) Opener {
+	return func(path string) (Handle, error) {
+		h, ok := handles[path]
+		if !ok {
+			return nil, syscall.ENOENT
+		}
+		return h, nil
+	}
+}
+
+func TestInspectDevice(t *testing.T) {
+	open := opener(map[string]*fakeHandle{
+		"/dev/video0": webcam,
+		"/dev/video1": webcamMeta,
+		"/dev/video2": captureCard,
+		"/dev/video3": legacy,
+	})
+
+	tests := []struct {
+		path     string
+		capture  bool
+		metadata bool
+		// Formate mit ihren Auflösungen und Bildraten, wie sie gemeldet werden
+		formats string
+	}{
+		{
+			path:    "/dev/video0",
+			capture: true,
+			formats: "[{YUYV false [{1920 1080 [5]} {640 480 [30]}]} {MJPG true [{1920 1080 [60 59.94 30]} {1280 720 [60 30]}]}]",
+		},
+		{
+			path:     "/dev/video1",
+			metadata: true,
+			formats:  "[]",
+		},
+		{
+			path:    "/dev/video2",
+			capture: true,
+			formats: "[{NV12 false [{3840 2160 [60]}]}]",
+		},
+		{
+			path:    "/dev/video3",
+			capture: true,
+			formats: "[{BGR3 false []}]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			dev, err := InspectDevice(tt.path, open)
+			if err != nil {
+				t.Fatalf("InspectDevice() error = %v", err)
+			}
+			if dev.Capture != tt.capture || dev.Metadata != tt.metadata {
+				t.Errorf("Capture, Metadata = %v, %v, want %v, %v", dev.Capture, dev.Metadata, tt.capture, tt.metadata)
+			}
+
+			formats := make([]string, 0, len(dev.Formats))
+			for _, f := range dev.Formats {
+				sizes := make([]string, 0, len(f.Sizes))
+				for _, s := range f.Sizes {
+					sizes = append(sizes, fmt.Sprint(s))
+				}
+				formats = append(formats, fmt.Sprintf("{%s %v %v}", f.FourCC, f.Compressed, sizes))
+			}
+			if got := fmt.Sprint(formats); got != tt.formats {
+				t.Errorf("formats = %s, want %s", got, tt.formats)
+			}
+		})
+	}
+
+	if _, err := InspectDevice("/dev/video9", open); err == nil {
+		t.Error("InspectDevice() error = nil for a missing device")
+	}
+}
+
+func TestSupports(t *testing.T) {
+	open := opener(map[string]*fakeHandle{"/dev/video0": webcam, "/dev/video2": captureCard})
+	cam, _ := InspectDevice("/dev/video0", open)
+	card, _ := InspectDevice("/dev/video2", open)
+
+	tests := []struct {
+		name          string
+		dev           Device
+		width, height uint32
+		fps           float64
+		want          bool
+	}{
+		{"1080p60 in MJPG", cam, 1920, 1080, 60, true},
+		{"1080p61", cam, 1920, 1080, 61, false},
+		{"640x480 only in YUYV", cam, 640, 480, 30, true},
+		{"720p60 in MJPG", cam, 1280, 720, 60, true},
+		{"unknown size", cam, 3840, 2160, 30, false},
+		{"stepwise maximum", card, 3840, 2160, 60, true},
+		// Von stufenlosen Geräten ist nur das Maximum bekannt
+		{"stepwise below the maximum", card, 1920, 1080, 60, false},
+		{"metadata node", Device{Metadata: true}, 1920, 1080, 60, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dev.Supports(tt.width, tt.height, tt.fps); got != tt.want {
+				t.Errorf("Supports(%d, %d, %g) = %v, want %v", tt.width, tt.height, tt.fps, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package video
+
+import "errors"
+
+// OpenDevice gibt es nur unter Linux.
+func OpenDevice(path string) (Handle, error) {
+	return nil, errors.New("v4l2 not supported on this platform")
+}
@@ -0,0 +1,38 @@
+// Package testutil holds fixtures shared by the probe tests.
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// FakeTree builds a /proc, /sys or /dev tree below a temp dir and
+// returns its root. files maps paths to contents, links maps link paths
+// to their targets, both relative to the root.
+func FakeTree(t *testing.T, files, links map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for path, target := range links {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(filepath.Join(root, target), full); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return root
+}
@@ -6,7 +6,9 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"kit.workmate/live-portal/internal/api"
 	"kit.workmate/live-portal/internal/api/handlers"
@@ -17,6 +19,7 @@ import (
 	"kit.workmate/live-portal/internal/services/twitch"
 	"kit.workmate/live-portal/internal/services/youtube"
 	"kit.workmate/live-portal/internal/storage"
+	"kit.workmate/live-portal/internal/systemd"
 	"kit.workmate/live-portal/internal/websocket"
 )
 
@@ -52,15 +55,65 @@ func main() {
 
 	// Initialize agent client
 	agentClient := agent.NewClient(cfg.Agent.URL, cfg.Agent.Timeout)
+	if cfg.Agent.APIKey != "" {
+		agentClient.UseAPIKey(cfg.Agent.APIKey)
+	}
+	if strings.HasPrefix(cfg.Agent.URL, "https://") {
+		if err := agentClient.UseTLS(cfg.Agent.TLS.CAFile, cfg.Agent.TLS.CertFile, cfg.Agent.TLS.KeyFile); err != nil {
+			log.Fatalf("Failed to set up agent TLS: %v", err)
+		}
+	}
+
+	// Initialize agent registry, seeded with the agent from the config file
+	agentStore, err := storage.NewAgentStore(cfg.Storage.Path)
+	if err != nil {
+		log.Fatalf("Failed to initialize agent store: %v", err)
+	}
+	defer agentStore.Close()
+
+	if err := agentStore.EnsureDefaultAgent(&storage.Agent{
+		Name:     "default",
+		URL:      cfg.Agent.URL,
+		APIKey:   cfg.Agent.APIKey,
+		CAFile:   cfg.Agent.TLS.CAFile,
+		CertFile: cfg.Agent.TLS.CertFile,
+		KeyFile:  cfg.Agent.TLS.KeyFile,
+	}); err != nil {
+		log.Fatalf("Failed to ensure default agent: %v", err)
+	}
 
-	// Initialize agent poller with callback to broadcast status via WebSocket
-	poller := agent.NewPoller(agentClient, cfg.Agent.PollingInterval, func(status *agent.Status) {
+	// Poll every agent and broadcast its status via WebSocket
+	fleet := agent.NewFleet(cfg.Agent.PollingInterval, func(id int, status *agent.Status) {
 		hub.Broadcast(websocket.Message{
-			Type: websocket.MessageTypeAgentStatus,
-			Data: status,
+			Type:    websocket.MessageTypeAgentStatus,
+			Data:    status,
+			AgentID: id,
 		})
 	})
-	poller.Start()
+	// Agents in push mode are stored with the polled ones
+	var registry *agent.Registry
+	if cfg.Ingest.Enabled {
+		registry = agent.NewRegistry(agentStore, cfg.Ingest.OfflineAfter)
+	}
+	agentsHandler := handlers.NewAgentsHandler(agentStore, fleet, registry, cfg.Agent.Timeout)
+	if err := agentsHandler.StartAll(); err != nil {
+		log.Fatalf("Failed to start agent pollers: %v", err)
+	}
+
+	// Let the agent tell us how to reach OBS
+	if cfg.OBS.Discover {
+		ws, err := agentClient.GetOBSWebSocket()
+		if err != nil {
+			log.Printf("Warning: OBS websocket discovery failed, using configured values: %v", err)
+		} else if !ws.Enabled {
+			log.Println("Warning: obs-websocket server is disabled in OBS")
+		} else {
+			cfg.OBS.Host = ws.Host
+			cfg.OBS.Port = ws.Port
+			cfg.OBS.Password = ws.Password
+			log.Printf("Discovered OBS websocket at %s:%d", ws.Host, ws.Port)
+		}
+	}
 
 	// Initialize OBS client
 	obsClient := obs.NewClient(cfg.OBS.Host, cfg.OBS.Port, cfg.OBS.Password)
@@ -154,28 +207,59 @@ func main() {
 	h := &api.Handlers{
 		Auth:      handlers.NewAuthHandler(userStore, jwtService),
 		Agent:     handlers.NewAgentHandler(agentClient),
+		Agents:    agentsHandler,
 		WebSocket: handlers.NewWebSocketHandler(hub),
 		OBS:       handlers.NewOBSHandler(obsClient),
 		Twitch:    handlers.NewTwitchHandler(twitchClient),
 		YouTube:   handlers.NewYouTubeHandler(youtubeClient),
 	}
 
+	if cfg.Ingest.Enabled {
+		h.Ingest = handlers.NewIngestHandler(registry, hub)
+	}
+
 	// Setup routes with JWT middleware
-	handler := api.Routes(h, jwtService)
+	handler := api.Routes(h, jwtService, cfg.Ingest.APIKeys)
 
 	// Create and start server
 	server := api.New(cfg.Server, handler)
-	server.Start()
+	listeners, err := systemd.Listeners()
+	if err != nil {
+		log.Fatalf("Socket activation: %v", err)
+	}
+	if len(listeners) > 0 {
+		for _, ln := range listeners[1:] {
+			log.Printf("Socket activation: ignoring %s, only the first socket is served", ln.Addr())
+			_ = ln.Close()
+		}
+		server.StartListener(listeners[0])
+	} else if err := server.Start(); err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
 
-	// Wait for interrupt signal
+	if err := systemd.Notify("READY=1"); err != nil {
+		log.Printf("systemd: %v", err)
+	}
+	done := make(chan struct{})
+	go watchdog(server, done)
+
+	// Wait for interrupt signal or the server failing. A failed server
+	// exits non-zero, so systemd restarts the portal.
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
-	<-stop
+	var serveErr error
+	select {
+	case <-stop:
+	case serveErr = <-server.Err():
+		log.Printf("HTTP server error: %v", serveErr)
+	}
 
 	log.Println("Stopping portal server")
+	_ = systemd.Notify("STOPPING=1")
+	close(done)
 
-	// Stop poller
-	poller.Stop()
+	// Stop pollers
+	fleet.Stop()
 
 	// Disconnect from OBS
 	if err := obsClient.Disconnect(); err != nil {
@@ -202,4 +286,37 @@ func main() {
 	_ = server.Shutdown(ctx)
 
 	log.Println("Portal server stopped")
+	if serveErr != nil {
+		cancel()
+		os.Exit(1)
+	}
+}
+
+// watchdog pings systemd's watchdog, if the unit has one, until done is
+// closed. Each ping needs the server to answer /health first, so a server
+// that hangs without failing gets the portal restarted too.
+func watchdog(server *api.Server, done <-chan struct{}) {
+	interval := systemd.WatchdogInterval()
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval/4)
+			err := server.Check(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("Watchdog: %v", err)
+				continue
+			}
+			_ = systemd.Notify("WATCHDOG=1")
+		case <-done:
+			return
+		}
+	}
 }
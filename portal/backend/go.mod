@@ -10,16 +10,16 @@ require (
 
 require (
 	github.com/andreykaipov/goobs v1.5.6
+	github.com/golang-jwt/jwt/v5 v5.3.0
 	github.com/gorilla/websocket v1.5.3
+	github.com/mattn/go-sqlite3 v1.14.33
+	golang.org/x/crypto v0.47.0
 )
 
 require (
 	github.com/buger/jsonparser v1.1.1 // indirect
-	github.com/golang-jwt/jwt/v5 v5.3.0 // indirect
 	github.com/hashicorp/logutils v1.0.0 // indirect
-	github.com/mattn/go-sqlite3 v1.14.33 // indirect
 	github.com/mitchellh/mapstructure v1.5.0 // indirect
 	github.com/mmcloughlin/profile v0.1.1 // indirect
 	github.com/nu7hatch/gouuid v0.0.0-20131221200532-179d4d0c4d8d // indirect
-	golang.org/x/crypto v0.47.0 // indirect
 )
@@ -0,0 +1,379 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"kit.workmate/live-portal/internal/services/agent"
+	"kit.workmate/live-portal/internal/storage"
+)
+
+// AgentsHandler manages the fleet of polled agents and lists them
+// together with the agents pushing through the registry
+type AgentsHandler struct {
+	store    *storage.AgentStore
+	fleet    *agent.Fleet
+	registry *agent.Registry // nil if ingest is disabled
+	timeout  time.Duration
+}
+
+func NewAgentsHandler(store *storage.AgentStore, fleet *agent.Fleet, registry *agent.Registry, timeout time.Duration) *AgentsHandler {
+	return &AgentsHandler{
+		store:    store,
+		fleet:    fleet,
+		registry: registry,
+		timeout:  timeout,
+	}
+}
+
+// AgentRequest is the payload for creating and updating agents.
+// An omitted api_key keeps the stored one on update.
+type AgentRequest struct {
+	Name     string  `json:"name"`
+	URL      string  `json:"url"`
+	APIKey   *string `json:"api_key"`
+	CAFile   string  `json:"ca_file"`
+	CertFile string  `json:"cert_file"`
+	KeyFile  string  `json:"key_file"`
+}
+
+// AgentResponse is a stored agent with the result of its last poll
+type AgentResponse struct {
+	*storage.Agent
+	agent.PollState
+}
+
+// StartAll starts polling every stored agent. An agent whose settings
+// don't work, e.g. because its CA file was removed, is logged and shown
+// with the error instead of keeping the portal from starting.
+func (h *AgentsHandler) StartAll() error {
+	agents, err := h.store.List()
+	if err != nil {
+		return err
+	}
+
+	for _, a := range agents {
+		if a.Mode == storage.ModePush {
+			continue
+		}
+		client, err := h.newClient(a)
+		if err != nil {
+			log.Printf("Agent %q can't be polled: %v", a.Name, err)
+			h.fleet.SetError(a.ID, err)
+			continue
+		}
+		h.fleet.Set(a.ID, client)
+	}
+
+	return nil
+}
+
+func (h *AgentsHandler) newClient(a *storage.Agent) (*agent.Client, error) {
+	client := agent.NewClient(a.URL, h.timeout)
+	if a.APIKey != "" {
+		client.UseAPIKey(a.APIKey)
+	}
+	if strings.HasPrefix(a.URL, "https://") {
+		if err := client.UseTLS(a.CAFile, a.CertFile, a.KeyFile); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+func (h *AgentsHandler) response(a *storage.Agent) AgentResponse {
+	if a.Mode == storage.ModePush {
+		var state agent.PollState
+		if h.registry != nil {
+			state = h.registry.State(a.ID)
+		}
+		return AgentResponse{Agent: a, PollState: state}
+	}
+
+	state, _ := h.fleet.State(a.ID)
+	return AgentResponse{Agent: a, PollState: state}
+}
+
+func (h *AgentsHandler) List(w http.ResponseWriter, r *http.Request) {
+	agents, err := h.store.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]AgentResponse, 0, len(agents))
+	for _, a := range agents {
+		resp = append(resp, h.response(a))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *AgentsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	a, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.response(a))
+}
+
+func (h *AgentsHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req AgentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	a := &storage.Agent{}
+	if err := req.apply(a); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Only store settings a client can be built from
+	client, err := h.newClient(a)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.store.Create(a)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	h.fleet.Set(created.ID, client)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(h.response(created))
+}
+
+func (h *AgentsHandler) Update(w http.ResponseWriter, r *http.Request) {
+	a, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	if a.Mode == storage.ModePush {
+		http.Error(w, "Push agents register themselves and can't be edited", http.StatusConflict)
+		return
+	}
+
+	var req AgentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := req.apply(a); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	client, err := h.newClient(a)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	updated, err := h.store.Update(a)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	h.fleet.Set(updated.ID, client)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.response(updated))
+}
+
+func (h *AgentsHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	a, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.Delete(a.ID); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	h.fleet.Remove(a.ID)
+	if h.registry != nil {
+		h.registry.Forget(a.ID)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AgentsHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	if pushed, ok := h.pushed(r); ok {
+		if pushed.Status == nil {
+			http.Error(w, "Agent has not reported yet", http.StatusServiceUnavailable)
+			return
+		}
+		writePushed(w, pushed.Status)
+		return
+	}
+
+	client, ok := h.client(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := client.GetStatus()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+func (h *AgentsHandler) GetCapabilities(w http.ResponseWriter, r *http.Request) {
+	if pushed, ok := h.pushed(r); ok {
+		writePushed(w, pushed.Capabilities)
+		return
+	}
+
+	client, ok := h.client(w, r)
+	if !ok {
+		return
+	}
+
+	caps, err := client.GetCapabilities()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(caps)
+}
+
+func (h *AgentsHandler) GetInfo(w http.ResponseWriter, r *http.Request) {
+	if pushed, ok := h.pushed(r); ok {
+		writePushed(w, pushed.Info)
+		return
+	}
+
+	client, ok := h.client(w, r)
+	if !ok {
+		return
+	}
+
+	info, err := client.GetInfo()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
+
+// lookup loads the agent named by the {id} URL parameter
+func (h *AgentsHandler) lookup(w http.ResponseWriter, r *http.Request) (*storage.Agent, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid agent ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	a, err := h.store.GetByID(id)
+	if err != nil {
+		writeStoreError(w, err)
+		return nil, false
+	}
+
+	return a, true
+}
+
+// pushed returns the last report of the agent named by {id} if it is a
+// push agent, which can't be asked directly
+func (h *AgentsHandler) pushed(r *http.Request) (agent.RegisteredAgent, bool) {
+	if h.registry == nil {
+		return agent.RegisteredAgent{}, false
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return agent.RegisteredAgent{}, false
+	}
+
+	a, err := h.store.GetByID(id)
+	if err != nil {
+		return agent.RegisteredAgent{}, false
+	}
+	return h.registry.Lookup(a)
+}
+
+func writePushed(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(data)
+}
+
+func (h *AgentsHandler) client(w http.ResponseWriter, r *http.Request) (*agent.Client, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid agent ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	client, ok := h.fleet.Client(id)
+	if !ok {
+		http.Error(w, "Agent not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	return client, true
+}
+
+func (req *AgentRequest) apply(a *storage.Agent) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return errors.New("name is required")
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an http:// or https:// URL")
+	}
+
+	if (req.CertFile == "") != (req.KeyFile == "") {
+		return errors.New("cert_file and key_file must be set together")
+	}
+
+	a.Name = req.Name
+	a.URL = strings.TrimSuffix(req.URL, "/")
+	a.CAFile = req.CAFile
+	a.CertFile = req.CertFile
+	a.KeyFile = req.KeyFile
+	if req.APIKey != nil {
+		a.APIKey = *req.APIKey
+	}
+
+	return nil
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, storage.ErrAgentNotFound):
+		http.Error(w, "Agent not found", http.StatusNotFound)
+	case errors.Is(err, storage.ErrAgentAlreadyExists):
+		http.Error(w, "Agent with this name already exists", http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"kit.workmate/live-portal/internal/auth"
+	"kit.workmate/live-portal/internal/services/agent"
+	ws "kit.workmate/live-portal/internal/websocket"
+)
+
+// maxIngestBody limits registrations and reports. A status with all video
+// formats of a few capture devices stays well below it.
+const maxIngestBody = 4 << 20
+
+// IngestHandler receives registrations and status reports from agents
+// running in push mode
+type IngestHandler struct {
+	registry *agent.Registry
+	hub      *ws.Hub
+}
+
+func NewIngestHandler(registry *agent.Registry, hub *ws.Hub) *IngestHandler {
+	return &IngestHandler{
+		registry: registry,
+		hub:      hub,
+	}
+}
+
+// AgentReport is broadcast via WebSocket for every pushed status
+type AgentReport struct {
+	AgentID      string             `json:"agent_id"`
+	Hostname     string             `json:"hostname"`
+	Status       *agent.Status      `json:"status"`
+	Capabilities agent.Capabilities `json:"capabilities"`
+}
+
+func (h *IngestHandler) Register(w http.ResponseWriter, r *http.Request) {
+	owner, _ := auth.GetIngestCredential(r)
+
+	var reg agent.Registration
+	r.Body = http.MaxBytesReader(w, r.Body, maxIngestBody)
+	if err := json.NewDecoder(r.Body).Decode(&reg); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if reg.AgentID == "" {
+		http.Error(w, "agent_id required", http.StatusBadRequest)
+		return
+	}
+
+	registered, err := h.registry.Register(reg, owner, r.RemoteAddr)
+	if err != nil {
+		writeIngestError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(registered)
+}
+
+func (h *IngestHandler) Report(w http.ResponseWriter, r *http.Request) {
+	owner, _ := auth.GetIngestCredential(r)
+
+	var report agent.Report
+	r.Body = http.MaxBytesReader(w, r.Body, maxIngestBody)
+	if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	registered, err := h.registry.Report(chi.URLParam(r, "id"), owner, report, r.RemoteAddr)
+	if err != nil {
+		writeIngestError(w, err)
+		return
+	}
+
+	h.hub.Broadcast(ws.Message{
+		Type: ws.MessageTypeAgentReport,
+		Data: AgentReport{
+			AgentID:      registered.ID,
+			Hostname:     registered.Hostname,
+			Status:       registered.Status,
+			Capabilities: registered.Capabilities,
+		},
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *IngestHandler) ListAgents(w http.ResponseWriter, r *http.Request) {
+	agents, err := h.registry.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(agents)
+}
+
+func (h *IngestHandler) GetAgent(w http.ResponseWriter, r *http.Request) {
+	registered, ok := h.registry.Get(chi.URLParam(r, "id"))
+	if !ok {
+		http.Error(w, "Agent not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(registered)
+}
+
+func writeIngestError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, agent.ErrNotRegistered):
+		// Tells the agent to register again
+		http.Error(w, "Agent not registered", http.StatusNotFound)
+	case errors.Is(err, agent.ErrNotOwner):
+		http.Error(w, "Agent ID belongs to another credential", http.StatusForbidden)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
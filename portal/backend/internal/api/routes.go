@@ -14,13 +14,17 @@ import (
 type Handlers struct {
 	Auth      *handlers.AuthHandler
 	Agent     *handlers.AgentHandler
+	Agents    *handlers.AgentsHandler
+	Ingest    *handlers.IngestHandler
 	WebSocket *handlers.WebSocketHandler
 	OBS       *handlers.OBSHandler
 	Twitch    *handlers.TwitchHandler
 	YouTube   *handlers.YouTubeHandler
 }
 
-func Routes(h *Handlers, jwtService *auth.JWTService) http.Handler {
+// Routes builds the router. Ingest endpoints are only mounted when
+// h.Ingest is set; agents authenticate there with ingestKeys or a user token.
+func Routes(h *Handlers, jwtService *auth.JWTService, ingestKeys []string) http.Handler {
 	r := chi.NewRouter()
 
 	// Middleware
@@ -63,6 +67,16 @@ func Routes(h *Handlers, jwtService *auth.JWTService) http.Handler {
 		r.Post("/auth/login", h.Auth.Login)
 		r.Get("/auth/verify", h.Auth.Verify)
 
+		// Agents in push mode
+		if h.Ingest != nil {
+			r.Group(func(r chi.Router) {
+				r.Use(auth.IngestMiddleware(jwtService, ingestKeys))
+
+				r.Post("/ingest/register", h.Ingest.Register)
+				r.Post("/ingest/agents/{id}/status", h.Ingest.Report)
+			})
+		}
+
 		// Protected routes group (JWT required)
 		r.Group(func(r chi.Router) {
 			// Apply JWT middleware to all routes in this group
@@ -76,6 +90,22 @@ func Routes(h *Handlers, jwtService *auth.JWTService) http.Handler {
 			r.Get("/agent/capabilities", h.Agent.GetCapabilities)
 			r.Get("/agent/info", h.Agent.GetInfo)
 
+			// Agent fleet
+			r.Get("/agents", h.Agents.List)
+			r.Post("/agents", h.Agents.Create)
+			r.Get("/agents/{id}", h.Agents.Get)
+			r.Put("/agents/{id}", h.Agents.Update)
+			r.Delete("/agents/{id}", h.Agents.Delete)
+			r.Get("/agents/{id}/status", h.Agents.GetStatus)
+			r.Get("/agents/{id}/capabilities", h.Agents.GetCapabilities)
+			r.Get("/agents/{id}/info", h.Agents.GetInfo)
+
+			// Agents reporting in push mode
+			if h.Ingest != nil {
+				r.Get("/ingest/agents", h.Ingest.ListAgents)
+				r.Get("/ingest/agents/{id}", h.Ingest.GetAgent)
+			}
+
 			// OBS control endpoints
 			r.Get("/obs/status", h.OBS.GetStatus)
 			r.Get("/obs/scenes", h.OBS.GetScenes)
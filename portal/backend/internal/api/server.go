@@ -2,14 +2,23 @@ package api
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"strconv"
+	"sync"
 
 	"kit.workmate/live-portal/internal/config"
 )
 
 type Server struct {
 	httpServer *http.Server
+	errs       chan error
+
+	mu   sync.Mutex
+	addr net.Addr
 }
 
 func New(cfg config.ServerConfig, handler http.Handler) *Server {
@@ -20,18 +29,82 @@ func New(cfg config.ServerConfig, handler http.Handler) *Server {
 			ReadTimeout:  cfg.Timeouts.Read,
 			WriteTimeout: cfg.Timeouts.Write,
 		},
+		errs: make(chan error, 1),
 	}
 }
 
-func (s *Server) Start() {
+// Start binds the listener, so an address in use is reported right away,
+// and serves in the background.
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+
+	s.StartListener(ln)
+	return nil
+}
+
+// StartListener serves on a listener someone else bound, e.g. systemd
+// with socket activation.
+func (s *Server) StartListener(ln net.Listener) {
+	s.mu.Lock()
+	s.addr = ln.Addr()
+	s.mu.Unlock()
+
 	go func() {
-		log.Printf("Portal server listening on %s", s.httpServer.Addr)
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("HTTP server error: %v", err)
+		log.Printf("Portal server listening on %s", ln.Addr())
+		if err := s.httpServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.errs <- err
 		}
 	}()
 }
 
+// Err delivers the error that stopped the server from serving.
+func (s *Server) Err() <-chan error {
+	return s.errs
+}
+
+// Check requests /health through the listener, so it fails if the server
+// stopped accepting or handling requests.
+func (s *Server) Check(ctx context.Context) error {
+	s.mu.Lock()
+	addr := s.addr
+	s.mu.Unlock()
+	if addr == nil {
+		return errors.New("server not started")
+	}
+
+	// A wildcard address is reached through loopback
+	network, target := addr.Network(), addr.String()
+	if tcp, ok := addr.(*net.TCPAddr); ok && tcp.IP.IsUnspecified() {
+		target = net.JoinHostPort("localhost", strconv.Itoa(tcp.Port))
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, target)
+		},
+		DisableKeepAlives: true,
+	}}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://portal/health", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("health check: %s", resp.Status)
+	}
+	return nil
+}
+
 func (s *Server) Shutdown(ctx context.Context) error {
 	log.Println("Shutting down portal server")
 	return s.httpServer.Shutdown(ctx)
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// IngestCredentialKey is the context key for the credential an agent
+// authenticated with, see GetIngestCredential
+const IngestCredentialKey contextKey = "ingest_credential"
+
+// IngestMiddleware authenticates agents pushing to the portal. It accepts
+// one of the configured API keys (given as "sha256:<hex>" hashes) or a
+// regular user token from /api/auth/login.
+func IngestMiddleware(jwtService *JWTService, keyHashes []string) func(http.Handler) http.Handler {
+	var hashes [][]byte
+	for _, h := range keyHashes {
+		if digest, err := hex.DecodeString(strings.TrimPrefix(h, "sha256:")); err == nil {
+			hashes = append(hashes, digest)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" {
+				http.Error(w, "No authorization header", http.StatusUnauthorized)
+				return
+			}
+
+			sum := sha256.Sum256([]byte(token))
+			for _, h := range hashes {
+				if subtle.ConstantTimeCompare(sum[:], h) == 1 {
+					ctx := context.WithValue(r.Context(), IngestCredentialKey, "key:"+hex.EncodeToString(h))
+					next.ServeHTTP(w, r.WithContext(ctx))
+					return
+				}
+			}
+
+			claims, err := jwtService.ValidateToken(token)
+			if err != nil {
+				http.Error(w, "Invalid token", http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), IngestCredentialKey, "user:"+claims.Username)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// GetIngestCredential returns who an agent authenticated as: "key:<hash>"
+// for an API key or "user:<name>" for a user token. Agents are bound to
+// the credential they registered with.
+func GetIngestCredential(r *http.Request) (string, bool) {
+	credential, ok := r.Context().Value(IngestCredentialKey).(string)
+	return credential, ok
+}
@@ -15,6 +15,7 @@ type Config struct {
 	Server  ServerConfig  `yaml:"server"`
 	Auth    AuthConfig    `yaml:"auth"`
 	Agent   AgentConfig   `yaml:"agent"`
+	Ingest  IngestConfig  `yaml:"ingest"`
 	OBS     OBSConfig     `yaml:"obs"`
 	Twitch  TwitchConfig  `yaml:"twitch"`
 	YouTube YouTubeConfig `yaml:"youtube"`
@@ -46,12 +47,36 @@ type DefaultUserConfig struct {
 }
 
 type AgentConfig struct {
-	URL             string        `yaml:"url"`
-	PollingInterval time.Duration `yaml:"polling_interval"`
-	Timeout         time.Duration `yaml:"timeout"`
+	URL             string         `yaml:"url"`
+	PollingInterval time.Duration  `yaml:"polling_interval"`
+	Timeout         time.Duration  `yaml:"timeout"`
+	APIKey          string         `yaml:"api_key"`
+	TLS             AgentTLSConfig `yaml:"tls"`
+}
+
+// AgentTLSConfig is used for https:// agent URLs
+type AgentTLSConfig struct {
+	CAFile   string `yaml:"ca_file"`
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+}
+
+// IngestConfig accepts status reports pushed by agents in portal mode
+type IngestConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// APIKeys agents may authenticate with, stored as "sha256:<hex>".
+	// Agents can also log in with portal user credentials instead.
+	APIKeys []string `yaml:"api_keys"`
+
+	// OfflineAfter marks an agent offline when it hasn't reported for this long
+	OfflineAfter time.Duration `yaml:"offline_after"`
 }
 
 type OBSConfig struct {
+	// Discover takes host, port and password from the agent's
+	// obs-websocket discovery instead of the values below
+	Discover       bool          `yaml:"discover"`
 	Host           string        `yaml:"host"`
 	Port           int           `yaml:"port"`
 	Password       string        `yaml:"password"`
@@ -27,6 +27,10 @@ func Default() *Config {
 			PollingInterval: 3 * time.Second,
 			Timeout:         5 * time.Second,
 		},
+		Ingest: IngestConfig{
+			Enabled:      false,
+			OfflineAfter: 90 * time.Second,
+		},
 		OBS: OBSConfig{
 			Host:           "localhost",
 			Port:           4455,
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
 )
 
 // Validate checks if the configuration is valid
@@ -19,6 +21,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("agent config: %w", err)
 	}
 
+	if err := c.Ingest.Validate(); err != nil {
+		return fmt.Errorf("ingest config: %w", err)
+	}
+
 	if err := c.OBS.Validate(); err != nil {
 		return fmt.Errorf("obs config: %w", err)
 	}
@@ -54,6 +60,25 @@ func (s *ServerConfig) Validate() error {
 	return nil
 }
 
+func (i *IngestConfig) Validate() error {
+	if !i.Enabled {
+		return nil
+	}
+
+	if i.OfflineAfter <= 0 {
+		return errors.New("offline_after must be positive")
+	}
+
+	for _, key := range i.APIKeys {
+		digest, ok := strings.CutPrefix(key, "sha256:")
+		if _, err := hex.DecodeString(digest); !ok || len(digest) != 64 || err != nil {
+			return errors.New("api keys must be \"sha256:<64 hex digits>\"")
+		}
+	}
+
+	return nil
+}
+
 func (a *AuthConfig) Validate() error {
 	if a.JWTSecret == "" {
 		return errors.New("JWT secret required")
@@ -91,6 +116,10 @@ func (a *AgentConfig) Validate() error {
 		return errors.New("timeout must be positive")
 	}
 
+	if (a.TLS.CertFile == "") != (a.TLS.KeyFile == "") {
+		return errors.New("tls cert_file and key_file must be set together")
+	}
+
 	return nil
 }
 
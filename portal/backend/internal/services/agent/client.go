@@ -1,14 +1,18 @@
 package agent
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
 	"time"
 )
 
 type Client struct {
 	baseURL    string
+	apiKey     string
 	httpClient *http.Client
 }
 
@@ -21,9 +25,57 @@ func NewClient(baseURL string, timeout time.Duration) *Client {
 	}
 }
 
+// UseAPIKey sends the key as bearer token with every request
+func (c *Client) UseAPIKey(key string) {
+	c.apiKey = key
+}
+
+// UseTLS verifies the agent against caFile (system roots if empty) and
+// presents the client certificate if certFile and keyFile are set
+func (c *Client) UseTLS(caFile, certFile, keyFile string) error {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read agent CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates in %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if certFile != "" && keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	c.httpClient.Transport = transport
+	return nil
+}
+
+// get performs an authenticated GET request against the agent
+func (c *Client) get(path string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	return c.httpClient.Do(req)
+}
+
 // GetStatus fetches the current status from the agent
 func (c *Client) GetStatus() (*Status, error) {
-	resp, err := c.httpClient.Get(c.baseURL + "/status")
+	resp, err := c.get("/status")
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch status: %w", err)
 	}
@@ -43,7 +95,7 @@ func (c *Client) GetStatus() (*Status, error) {
 
 // GetCapabilities fetches the agent capabilities
 func (c *Client) GetCapabilities() (*Capabilities, error) {
-	resp, err := c.httpClient.Get(c.baseURL + "/capabilities")
+	resp, err := c.get("/capabilities")
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch capabilities: %w", err)
 	}
@@ -63,7 +115,7 @@ func (c *Client) GetCapabilities() (*Capabilities, error) {
 
 // GetInfo fetches the agent build info
 func (c *Client) GetInfo() (*Info, error) {
-	resp, err := c.httpClient.Get(c.baseURL + "/info")
+	resp, err := c.get("/info")
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch info: %w", err)
 	}
@@ -80,3 +132,23 @@ func (c *Client) GetInfo() (*Info, error) {
 
 	return &info, nil
 }
+
+// GetOBSWebSocket fetches the obs-websocket connection details from the agent
+func (c *Client) GetOBSWebSocket() (*OBSWebSocket, error) {
+	resp, err := c.get("/obs/websocket")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch obs-websocket config: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("agent returned status %d", resp.StatusCode)
+	}
+
+	var ws OBSWebSocket
+	if err := json.NewDecoder(resp.Body).Decode(&ws); err != nil {
+		return nil, fmt.Errorf("failed to decode obs-websocket config: %w", err)
+	}
+
+	return &ws, nil
+}
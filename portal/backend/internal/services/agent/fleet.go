@@ -0,0 +1,113 @@
+package agent
+
+import (
+	"sync"
+	"time"
+)
+
+// FleetCallback receives the status of one agent in the fleet
+type FleetCallback func(id int, status *Status)
+
+// Fleet runs one poller per agent
+type Fleet struct {
+	mu       sync.RWMutex
+	members  map[int]*member
+	interval time.Duration
+	callback FleetCallback
+}
+
+type member struct {
+	client *Client
+	poller *Poller
+	// err is set instead of client and poller for an agent whose stored
+	// settings can't be turned into a client, e.g. a missing CA file
+	err string
+}
+
+func NewFleet(interval time.Duration, callback FleetCallback) *Fleet {
+	return &Fleet{
+		members:  make(map[int]*member),
+		interval: interval,
+		callback: callback,
+	}
+}
+
+// Set starts polling an agent, replacing a previous client for the same ID
+func (f *Fleet) Set(id int, client *Client) {
+	poller := NewPoller(client, f.interval, func(status *Status) {
+		if f.callback != nil {
+			f.callback(id, status)
+		}
+	})
+
+	f.mu.Lock()
+	f.stopMember(id)
+	f.members[id] = &member{client: client, poller: poller}
+	f.mu.Unlock()
+
+	poller.Start()
+}
+
+// SetError records that an agent can't be polled at all. It shows up in
+// State until the agent is fixed with Set or removed.
+func (f *Fleet) SetError(id int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.stopMember(id)
+	f.members[id] = &member{err: err.Error()}
+}
+
+// stopMember stops the poller of an agent, if it has one. f.mu must be held.
+func (f *Fleet) stopMember(id int) {
+	if m, ok := f.members[id]; ok && m.poller != nil {
+		m.poller.Stop()
+	}
+}
+
+// Remove stops polling an agent
+func (f *Fleet) Remove(id int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.stopMember(id)
+	delete(f.members, id)
+}
+
+// Client returns the client for an agent
+func (f *Fleet) Client(id int) (*Client, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	m, ok := f.members[id]
+	if !ok || m.client == nil {
+		return nil, false
+	}
+	return m.client, true
+}
+
+// State returns the result of the last poll of an agent
+func (f *Fleet) State(id int) (PollState, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	m, ok := f.members[id]
+	if !ok {
+		return PollState{}, false
+	}
+	if m.poller == nil {
+		return PollState{LastError: m.err}, true
+	}
+	return m.poller.State(), true
+}
+
+// Stop stops all pollers
+func (f *Fleet) Stop() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for id := range f.members {
+		f.stopMember(id)
+		delete(f.members, id)
+	}
+}
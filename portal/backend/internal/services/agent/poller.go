@@ -2,6 +2,7 @@ package agent
 
 import (
 	"log"
+	"sync"
 	"time"
 )
 
@@ -12,6 +13,17 @@ type Poller struct {
 	interval time.Duration
 	callback StatusCallback
 	stop     chan struct{}
+
+	mu        sync.RWMutex
+	lastSeen  time.Time
+	lastError string
+}
+
+// PollState tells whether the agent answered the last poll
+type PollState struct {
+	Online    bool       `json:"online"`
+	LastSeen  *time.Time `json:"last_seen,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
 }
 
 func NewPoller(client *Client, interval time.Duration, callback StatusCallback) *Poller {
@@ -44,8 +56,18 @@ func (p *Poller) Start() {
 
 func (p *Poller) fetchAndNotify() {
 	status, err := p.client.GetStatus()
+
+	p.mu.Lock()
+	if err != nil {
+		p.lastError = err.Error()
+	} else {
+		p.lastError = ""
+		p.lastSeen = time.Now()
+	}
+	p.mu.Unlock()
+
 	if err != nil {
-		log.Printf("Failed to fetch agent status: %v", err)
+		log.Printf("Failed to fetch agent status from %s: %v", p.client.baseURL, err)
 		return
 	}
 
@@ -54,6 +76,22 @@ func (p *Poller) fetchAndNotify() {
 	}
 }
 
+// State returns the result of the last poll
+func (p *Poller) State() PollState {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	state := PollState{
+		Online:    p.lastError == "" && !p.lastSeen.IsZero(),
+		LastError: p.lastError,
+	}
+	if !p.lastSeen.IsZero() {
+		lastSeen := p.lastSeen
+		state.LastSeen = &lastSeen
+	}
+	return state
+}
+
 func (p *Poller) Stop() {
 	close(p.stop)
 }
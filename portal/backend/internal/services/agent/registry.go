@@ -0,0 +1,238 @@
+package agent
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"kit.workmate/live-portal/internal/storage"
+)
+
+var (
+	// ErrNotRegistered means the agent has to register before reporting
+	ErrNotRegistered = errors.New("agent not registered")
+	// ErrNotOwner means the agent ID is bound to another ingest credential
+	ErrNotOwner = errors.New("agent registered with another credential")
+)
+
+// RegisteredAgent is an agent that reports to the portal in push mode
+type RegisteredAgent struct {
+	ID           string       `json:"id"`
+	AgentID      int          `json:"agent_id"` // ID in /api/agents
+	Name         string       `json:"name"`
+	Hostname     string       `json:"hostname"`
+	Info         Info         `json:"info"`
+	Capabilities Capabilities `json:"capabilities"`
+	Status       *Status      `json:"status,omitempty"`
+	RemoteAddr   string       `json:"remote_addr,omitempty"`
+	RegisteredAt time.Time    `json:"registered_at"`
+	LastSeen     *time.Time   `json:"last_seen,omitempty"`
+	Online       bool         `json:"online"`
+}
+
+// Registry keeps the agents in push mode. Which agents exist, and which
+// credential each one is bound to, is stored with the polled agents;
+// their reports are only kept in memory, agents push again after a
+// portal restart.
+type Registry struct {
+	store        *storage.AgentStore
+	offlineAfter time.Duration
+
+	mu   sync.RWMutex
+	live map[int]*liveReport // by storage ID
+}
+
+type liveReport struct {
+	info         Info
+	capabilities Capabilities
+	status       *Status
+	remoteAddr   string
+	registeredAt time.Time
+	lastSeen     time.Time
+}
+
+func NewRegistry(store *storage.AgentStore, offlineAfter time.Duration) *Registry {
+	return &Registry{
+		store:        store,
+		offlineAfter: offlineAfter,
+		live:         make(map[int]*liveReport),
+	}
+}
+
+// Register adds an agent bound to owner, or refreshes its details. An
+// agent ID registered by another credential is rejected with ErrNotOwner.
+func (r *Registry) Register(reg Registration, owner, remoteAddr string) (RegisteredAgent, error) {
+	a, err := r.store.GetByPushID(reg.AgentID)
+	switch {
+	case errors.Is(err, storage.ErrAgentNotFound):
+		a, err = r.create(reg, owner)
+		if err != nil {
+			return RegisteredAgent{}, err
+		}
+	case err != nil:
+		return RegisteredAgent{}, err
+	case a.Owner != owner:
+		return RegisteredAgent{}, ErrNotOwner
+	case a.Hostname != reg.Hostname:
+		a.Hostname = reg.Hostname
+		if a, err = r.store.Update(a); err != nil {
+			return RegisteredAgent{}, err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	live, ok := r.live[a.ID]
+	if !ok {
+		live = &liveReport{}
+		r.live[a.ID] = live
+	}
+	live.info = reg.Info
+	live.capabilities = reg.Capabilities
+	live.remoteAddr = remoteAddr
+	live.registeredAt = now
+	live.lastSeen = now
+
+	return r.snapshot(a, now), nil
+}
+
+// create stores a new push agent named after its host, or after host and
+// ID if another agent already has that name.
+func (r *Registry) create(reg Registration, owner string) (*storage.Agent, error) {
+	name := reg.Hostname
+	if name == "" {
+		name = reg.AgentID
+	}
+
+	a := &storage.Agent{Name: name, PushID: reg.AgentID, Hostname: reg.Hostname, Owner: owner}
+	created, err := r.store.Create(a)
+	if errors.Is(err, storage.ErrAgentAlreadyExists) && name != reg.AgentID {
+		a.Name = name + "-" + reg.AgentID
+		created, err = r.store.Create(a)
+	}
+	return created, err
+}
+
+// Report stores a status update from the agent registered as id by owner.
+func (r *Registry) Report(id, owner string, report Report, remoteAddr string) (RegisteredAgent, error) {
+	a, err := r.store.GetByPushID(id)
+	if errors.Is(err, storage.ErrAgentNotFound) {
+		return RegisteredAgent{}, ErrNotRegistered
+	}
+	if err != nil {
+		return RegisteredAgent{}, err
+	}
+	if a.Owner != owner {
+		return RegisteredAgent{}, ErrNotOwner
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	live, ok := r.live[a.ID]
+	if !ok {
+		// Registered before a portal restart, the registration details
+		// come with the next registration
+		return RegisteredAgent{}, ErrNotRegistered
+	}
+
+	now := time.Now()
+	live.status = report.Status
+	live.capabilities = report.Capabilities
+	live.remoteAddr = remoteAddr
+	live.lastSeen = now
+
+	return r.snapshot(a, now), nil
+}
+
+// Get returns the push agent registered as id
+func (r *Registry) Get(id string) (RegisteredAgent, bool) {
+	a, err := r.store.GetByPushID(id)
+	if err != nil {
+		return RegisteredAgent{}, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.snapshot(a, time.Now()), true
+}
+
+// Lookup returns the push details of a stored agent, false for polled ones
+func (r *Registry) Lookup(a *storage.Agent) (RegisteredAgent, bool) {
+	if a.Mode != storage.ModePush {
+		return RegisteredAgent{}, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.snapshot(a, time.Now()), true
+}
+
+// List returns all push agents sorted by name
+func (r *Registry) List() ([]RegisteredAgent, error) {
+	agents, err := r.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	list := []RegisteredAgent{}
+	for _, a := range agents {
+		if a.Mode == storage.ModePush {
+			list = append(list, r.snapshot(a, now))
+		}
+	}
+	return list, nil
+}
+
+// Forget drops the reports of a deleted agent
+func (r *Registry) Forget(id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.live, id)
+}
+
+// State returns the push agent's reachability in the same form as a
+// polled agent's
+func (r *Registry) State(id int) PollState {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	live, ok := r.live[id]
+	if !ok {
+		return PollState{}
+	}
+	lastSeen := live.lastSeen
+	return PollState{
+		Online:   time.Since(lastSeen) < r.offlineAfter,
+		LastSeen: &lastSeen,
+	}
+}
+
+// snapshot combines the stored agent with its last report. r.mu must be held.
+func (r *Registry) snapshot(a *storage.Agent, now time.Time) RegisteredAgent {
+	reg := RegisteredAgent{
+		ID:           a.PushID,
+		AgentID:      a.ID,
+		Name:         a.Name,
+		Hostname:     a.Hostname,
+		RegisteredAt: a.CreatedAt,
+	}
+
+	if live, ok := r.live[a.ID]; ok {
+		reg.Info = live.info
+		reg.Capabilities = live.capabilities
+		reg.Status = live.status
+		reg.RemoteAddr = live.remoteAddr
+		reg.RegisteredAt = live.registeredAt
+		lastSeen := live.lastSeen
+		reg.LastSeen = &lastSeen
+		reg.Online = now.Sub(lastSeen) < r.offlineAfter
+	}
+	return reg
+}
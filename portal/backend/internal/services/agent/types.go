@@ -4,33 +4,295 @@ import "time"
 
 // Status represents the agent's status response
 type Status struct {
-	Timestamp time.Time   `json:"timestamp"`
-	Hostname  string      `json:"hostname"`
-	Headless  bool        `json:"headless"`
-	Video     VideoStatus `json:"video"`
-	Audio     AudioStatus `json:"audio"`
-	OBS       OBSStatus   `json:"obs"`
-	GPU       GPUStatus   `json:"gpu"`
+	Timestamp time.Time     `json:"timestamp"`
+	Hostname  string        `json:"hostname"`
+	Headless  bool          `json:"headless"`
+	Video     VideoStatus   `json:"video"`
+	Audio     AudioStatus   `json:"audio"`
+	OBS       OBSStatus     `json:"obs"`
+	GPU       GPUStatus     `json:"gpu"`
+	Load      *LoadStatus   `json:"load,omitempty"`
+	Display   DisplayStatus `json:"display"`
+	USB       USBStatus     `json:"usb"`
+
+	ExpectedDevices []ExpectedDevice `json:"expected_devices"`
+
+	Probes map[string]ProbeStatus `json:"probes"`
 }
 
 type VideoStatus struct {
-	DeviceCount int      `json:"device_count"`
-	Devices     []string `json:"devices"`
+	DeviceCount int           `json:"device_count"`
+	Devices     []string      `json:"devices"`
+	Details     []VideoDevice `json:"details"`
+}
+
+type VideoDevice struct {
+	Path     string         `json:"path"`
+	Driver   string         `json:"driver,omitempty"`
+	Card     string         `json:"card,omitempty"`
+	BusInfo  string         `json:"bus_info,omitempty"`
+	Capture  bool           `json:"capture"`
+	Metadata bool           `json:"metadata"`
+	Formats  []VideoFormat  `json:"formats,omitempty"`
+	Error    string         `json:"error,omitempty"`
+	Identity DeviceIdentity `json:"identity"`
+	PCI      *PCIDevice     `json:"pci,omitempty"`
+	USB      *USBDevice     `json:"usb,omitempty"`
+}
+
+// DeviceIdentity holds the names a device keeps across reboots and replugs
+type DeviceIdentity struct {
+	ID     string   `json:"id,omitempty"`
+	ByID   []string `json:"by_id"`
+	ByPath []string `json:"by_path"`
+	Serial string   `json:"serial,omitempty"`
+	USB    string   `json:"usb,omitempty"`
+}
+
+type VideoFormat struct {
+	FourCC      string           `json:"fourcc"`
+	Description string           `json:"description"`
+	Compressed  bool             `json:"compressed"`
+	Sizes       []VideoFrameSize `json:"sizes,omitempty"`
+}
+
+type VideoFrameSize struct {
+	Width  uint32    `json:"width"`
+	Height uint32    `json:"height"`
+	FPS    []float64 `json:"fps,omitempty"`
 }
 
 type AudioStatus struct {
-	Backend string `json:"backend"`
-	Ready   bool   `json:"ready"`
+	Backend       string      `json:"backend"`
+	Ready         bool        `json:"ready"`
+	Sinks         []AudioNode `json:"sinks"`
+	Sources       []AudioNode `json:"sources"`
+	DefaultSink   string      `json:"default_sink,omitempty"`
+	DefaultSource string      `json:"default_source,omitempty"`
+	Cards         []AudioCard `json:"cards,omitempty"`
+}
+
+type AudioCard struct {
+	Index  int    `json:"index"`
+	ID     string `json:"id"`
+	Driver string `json:"driver"`
+	Name   string `json:"name"`
+
+	Identity DeviceIdentity `json:"identity"`
+}
+
+type AudioNode struct {
+	ID          int     `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Channels    int     `json:"channels,omitempty"`
+	SampleRate  int     `json:"sample_rate,omitempty"`
+	Muted       bool    `json:"muted"`
+	Volume      float64 `json:"volume"`
 }
 
 type OBSStatus struct {
-	Running bool `json:"running"`
+	Running   bool              `json:"running"`
+	Process   *OBSProcess       `json:"process,omitempty"`
+	WebSocket *OBSWebSocketInfo `json:"websocket,omitempty"`
+}
+
+type OBSWebSocketInfo struct {
+	Enabled      bool   `json:"enabled"`
+	Port         int    `json:"port"`
+	AuthRequired bool   `json:"auth_required"`
+	ConfigPath   string `json:"config_path"`
+}
+
+// OBSWebSocket holds the obs-websocket connection details discovered by the agent
+type OBSWebSocket struct {
+	Host         string `json:"host"`
+	Port         int    `json:"port"`
+	Enabled      bool   `json:"enabled"`
+	AuthRequired bool   `json:"auth_required"`
+	Password     string `json:"password,omitempty"`
+}
+
+type OBSProcess struct {
+	PID         int    `json:"pid"`
+	Name        string `json:"name"`
+	Exe         string `json:"exe,omitempty"`
+	InstallType string `json:"install_type"`
+
+	StartedAt     time.Time `json:"started_at"`
+	UptimeSeconds float64   `json:"uptime_seconds"`
+	CPUPercent    float64   `json:"cpu_percent"`
+	RSSBytes      uint64    `json:"rss_bytes"`
+	Threads       int       `json:"threads"`
+
+	Args           []string `json:"args"`
+	Profile        string   `json:"profile,omitempty"`
+	Collection     string   `json:"collection,omitempty"`
+	Scene          string   `json:"scene,omitempty"`
+	StartStreaming bool     `json:"start_streaming"`
+	StartRecording bool     `json:"start_recording"`
+	MinimizeToTray bool     `json:"minimize_to_tray"`
+	Portable       bool     `json:"portable"`
+}
+
+type ProbeStatus struct {
+	DurationMS  float64    `json:"duration_ms"`
+	Error       string     `json:"error,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	Stale       bool       `json:"stale,omitempty"`
 }
 
 type GPUStatus struct {
-	Present     bool     `json:"present"`
-	Vendors     []string `json:"vendors,omitempty"`
-	RenderNodes []string `json:"render_nodes,omitempty"`
+	Present     bool        `json:"present"`
+	Vendors     []string    `json:"vendors,omitempty"`
+	RenderNodes []string    `json:"render_nodes,omitempty"`
+	Devices     []GPUDevice `json:"devices,omitempty"`
+}
+
+type GPUDevice struct {
+	RenderNode string `json:"render_node"`
+	Card       string `json:"card,omitempty"`
+	Vendor     string `json:"vendor"`
+	Driver     string `json:"driver,omitempty"`
+
+	PCI *PCIDevice `json:"pci,omitempty"`
+
+	BusyPercent    *int     `json:"busy_percent,omitempty"`
+	VRAMUsedBytes  *uint64  `json:"vram_used_bytes,omitempty"`
+	VRAMTotalBytes *uint64  `json:"vram_total_bytes,omitempty"`
+	FreqMHz        *int     `json:"freq_mhz,omitempty"`
+	MaxFreqMHz     *int     `json:"max_freq_mhz,omitempty"`
+	TemperatureC   *float64 `json:"temperature_c,omitempty"`
+	PowerW         *float64 `json:"power_w,omitempty"`
+}
+
+type PCIDevice struct {
+	Slot     string `json:"slot"`
+	VendorID string `json:"vendor_id"`
+	DeviceID string `json:"device_id"`
+	Class    string `json:"class,omitempty"`
+	Vendor   string `json:"vendor,omitempty"`
+	Model    string `json:"model,omitempty"`
+	Driver   string `json:"driver,omitempty"`
+}
+
+// LoadStatus is the CPU, memory, disk and network load of the agent host
+type LoadStatus struct {
+	CPU      LoadCPU         `json:"cpu"`
+	Memory   LoadMemory      `json:"memory"`
+	Disks    []LoadDisk      `json:"disks"`
+	Network  []LoadInterface `json:"network"`
+	Warnings []string        `json:"warnings,omitempty"`
+}
+
+type LoadCPU struct {
+	UsagePercent float64   `json:"usage_percent"`
+	Cores        []float64 `json:"cores"`
+	Load1        float64   `json:"load1"`
+	Load5        float64   `json:"load5"`
+	Load15       float64   `json:"load15"`
+}
+
+type LoadMemory struct {
+	TotalBytes     uint64  `json:"total_bytes"`
+	AvailableBytes uint64  `json:"available_bytes"`
+	UsedBytes      uint64  `json:"used_bytes"`
+	UsedPercent    float64 `json:"used_percent"`
+	SwapTotalBytes uint64  `json:"swap_total_bytes"`
+	SwapUsedBytes  uint64  `json:"swap_used_bytes"`
+}
+
+type LoadDisk struct {
+	Path        string  `json:"path"`
+	TotalBytes  uint64  `json:"total_bytes"`
+	FreeBytes   uint64  `json:"free_bytes"`
+	UsedPercent float64 `json:"used_percent"`
+	Error       string  `json:"error,omitempty"`
+}
+
+type LoadInterface struct {
+	Name          string  `json:"name"`
+	RxBytes       uint64  `json:"rx_bytes"`
+	TxBytes       uint64  `json:"tx_bytes"`
+	RxBytesPerSec float64 `json:"rx_bytes_per_sec"`
+	TxBytesPerSec float64 `json:"tx_bytes_per_sec"`
+}
+
+type DisplayStatus struct {
+	Available bool             `json:"available"`
+	Sessions  []DisplaySession `json:"sessions"`
+	Sockets   []DisplaySocket  `json:"sockets"`
+	Monitors  []Monitor        `json:"monitors"`
+}
+
+type DisplaySession struct {
+	ID      string `json:"id"`
+	User    string `json:"user"`
+	UID     int    `json:"uid"`
+	Type    string `json:"type"`
+	Class   string `json:"class"`
+	State   string `json:"state"`
+	Active  bool   `json:"active"`
+	Seat    string `json:"seat,omitempty"`
+	Display string `json:"display,omitempty"`
+	Desktop string `json:"desktop,omitempty"`
+	Remote  bool   `json:"remote"`
+}
+
+type DisplaySocket struct {
+	Type    string `json:"type"`
+	Path    string `json:"path"`
+	Display string `json:"display"`
+	UID     int    `json:"uid"`
+}
+
+type Monitor struct {
+	Card          string   `json:"card"`
+	Connector     string   `json:"connector"`
+	Enabled       bool     `json:"enabled"`
+	Name          string   `json:"name,omitempty"`
+	Manufacturer  string   `json:"manufacturer,omitempty"`
+	ProductCode   uint16   `json:"product_code,omitempty"`
+	Serial        string   `json:"serial,omitempty"`
+	WidthCM       int      `json:"width_cm,omitempty"`
+	HeightCM      int      `json:"height_cm,omitempty"`
+	PreferredMode string   `json:"preferred_mode,omitempty"`
+	Modes         []string `json:"modes"`
+}
+
+// ExpectedDevice is an entry of the agent's expected-device manifest
+type ExpectedDevice struct {
+	Name    string   `json:"name"`
+	Kind    string   `json:"kind"`
+	Present bool     `json:"present"`
+	Paths   []string `json:"paths"`
+}
+
+type USBStatus struct {
+	Devices []USBDevice `json:"devices"`
+}
+
+type USBDevice struct {
+	Path          string   `json:"path"`
+	Bus           int      `json:"bus"`
+	Device        int      `json:"device"`
+	VendorID      string   `json:"vendor_id"`
+	ProductID     string   `json:"product_id"`
+	Vendor        string   `json:"vendor,omitempty"`
+	Model         string   `json:"model,omitempty"`
+	Manufacturer  string   `json:"manufacturer,omitempty"`
+	Product       string   `json:"product,omitempty"`
+	Serial        string   `json:"serial,omitempty"`
+	Version       string   `json:"version"`
+	SpeedMbps     float64  `json:"speed_mbps"`
+	Speed         string   `json:"speed"`
+	MaxSpeedMbps  float64  `json:"max_speed_mbps,omitempty"`
+	PortSpeedMbps float64  `json:"port_speed_mbps,omitempty"`
+	Degraded      bool     `json:"degraded"`
+	MaxPowerMA    int      `json:"max_power_ma"`
+	SelfPowered   bool     `json:"self_powered"`
+	Classes       []string `json:"classes"`
+	Drivers       []string `json:"drivers"`
 }
 
 // Capabilities represents agent capabilities
@@ -38,6 +300,23 @@ type Capabilities struct {
 	CanVideo  bool `json:"can_video"`
 	CanAudio  bool `json:"can_audio"`
 	CanStream bool `json:"can_stream"`
+
+	LoadOK   bool     `json:"load_ok"`
+	Warnings []string `json:"warnings,omitempty"`
+
+	Details map[string]CapabilityResult `json:"details,omitempty"`
+}
+
+// CapabilityResult lists the conditions that keep a capability unavailable.
+type CapabilityResult struct {
+	Available bool             `json:"available"`
+	Unmet     []UnmetCondition `json:"unmet,omitempty"`
+}
+
+type UnmetCondition struct {
+	Condition string `json:"condition"`
+	Reason    string `json:"reason"`
+	Actual    any    `json:"actual,omitempty"`
 }
 
 // Info represents agent build info
@@ -66,3 +345,17 @@ type CPUInfo struct {
 type MemoryInfo struct {
 	TotalMB int `json:"total_mb"`
 }
+
+// Registration is sent by agents in push mode when they start
+type Registration struct {
+	AgentID      string       `json:"agent_id"`
+	Hostname     string       `json:"hostname"`
+	Info         Info         `json:"info"`
+	Capabilities Capabilities `json:"capabilities"`
+}
+
+// Report is a status update pushed by an agent
+type Report struct {
+	Status       *Status      `json:"status"`
+	Capabilities Capabilities `json:"capabilities"`
+}
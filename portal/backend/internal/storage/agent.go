@@ -0,0 +1,265 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	ErrAgentNotFound      = errors.New("agent not found")
+	ErrAgentAlreadyExists = errors.New("agent already exists")
+)
+
+// Agent modes
+const (
+	ModePoll = "poll"
+	ModePush = "push"
+)
+
+// Agent is a workmate agent the portal polls, or one that pushes its
+// status through /api/ingest
+type Agent struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Mode      string `json:"mode"`
+	URL       string `json:"url,omitempty"`
+	APIKey    string `json:"-"` // Never expose in JSON
+	HasAPIKey bool   `json:"has_api_key"`
+	CAFile    string `json:"ca_file,omitempty"`
+	CertFile  string `json:"cert_file,omitempty"`
+	KeyFile   string `json:"key_file,omitempty"`
+
+	// PushID is the ID a push agent registered with, Hostname the host it
+	// reported and Owner the ingest credential it authenticated with
+	PushID   string `json:"push_id,omitempty"`
+	Hostname string `json:"hostname,omitempty"`
+	Owner    string `json:"-"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AgentStore handles agent persistence
+type AgentStore struct {
+	db *sql.DB
+}
+
+// NewAgentStore creates a new agent store
+func NewAgentStore(dbPath string) (*AgentStore, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &AgentStore{db: db}
+	if err := store.init(); err != nil {
+		return nil, err
+	}
+	if err := store.migrate(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// init creates the agents table if it doesn't exist
+func (s *AgentStore) init() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS agents (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			url TEXT NOT NULL,
+			api_key TEXT NOT NULL DEFAULT '',
+			ca_file TEXT NOT NULL DEFAULT '',
+			cert_file TEXT NOT NULL DEFAULT '',
+			key_file TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+	`
+	_, err := s.db.Exec(query)
+	return err
+}
+
+// migrate adds the push agent columns to tables created before them
+func (s *AgentStore) migrate() error {
+	rows, err := s.db.Query("PRAGMA table_info(agents)")
+	if err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, typ        string
+			dflt             sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	rows.Close()
+
+	columns := []struct{ name, def string }{
+		{"push_id", "TEXT"},
+		{"hostname", "TEXT NOT NULL DEFAULT ''"},
+		{"owner", "TEXT NOT NULL DEFAULT ''"},
+	}
+	for _, c := range columns {
+		if existing[c.name] {
+			continue
+		}
+		if _, err := s.db.Exec("ALTER TABLE agents ADD COLUMN " + c.name + " " + c.def); err != nil {
+			return err
+		}
+	}
+
+	// NULL for polled agents, which SQLite doesn't count as duplicates
+	_, err = s.db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS agents_push_id ON agents (push_id)")
+	return err
+}
+
+const agentColumns = "id, name, url, api_key, ca_file, cert_file, key_file, push_id, hostname, owner, created_at, updated_at"
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAgent(row scanner) (*Agent, error) {
+	a := &Agent{}
+	var pushID sql.NullString
+	err := row.Scan(&a.ID, &a.Name, &a.URL, &a.APIKey, &a.CAFile, &a.CertFile, &a.KeyFile,
+		&pushID, &a.Hostname, &a.Owner, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	a.PushID = pushID.String
+	a.HasAPIKey = a.APIKey != ""
+	a.Mode = ModePoll
+	if a.PushID != "" {
+		a.Mode = ModePush
+	}
+	return a, nil
+}
+
+// nullIfEmpty stores "" as NULL, for the unique push_id
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// Create stores a new agent
+func (s *AgentStore) Create(a *Agent) (*Agent, error) {
+	result, err := s.db.Exec(
+		`INSERT INTO agents (name, url, api_key, ca_file, cert_file, key_file, push_id, hostname, owner)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.Name, a.URL, a.APIKey, a.CAFile, a.CertFile, a.KeyFile, nullIfEmpty(a.PushID), a.Hostname, a.Owner,
+	)
+	if err != nil {
+		// SQLite constraint error (UNIQUE)
+		return nil, ErrAgentAlreadyExists
+	}
+
+	id, _ := result.LastInsertId()
+	return s.GetByID(int(id))
+}
+
+// GetByID retrieves an agent by ID
+func (s *AgentStore) GetByID(id int) (*Agent, error) {
+	a, err := scanAgent(s.db.QueryRow("SELECT "+agentColumns+" FROM agents WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrAgentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// GetByPushID retrieves a push agent by the ID it registered with
+func (s *AgentStore) GetByPushID(pushID string) (*Agent, error) {
+	a, err := scanAgent(s.db.QueryRow("SELECT "+agentColumns+" FROM agents WHERE push_id = ?", pushID))
+	if err == sql.ErrNoRows {
+		return nil, ErrAgentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// List returns all agents ordered by name
+func (s *AgentStore) List() ([]*Agent, error) {
+	rows, err := s.db.Query("SELECT " + agentColumns + " FROM agents ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	agents := []*Agent{}
+	for rows.Next() {
+		a, err := scanAgent(rows)
+		if err != nil {
+			return nil, err
+		}
+		agents = append(agents, a)
+	}
+
+	return agents, rows.Err()
+}
+
+// Update overwrites an agent's settings
+func (s *AgentStore) Update(a *Agent) (*Agent, error) {
+	result, err := s.db.Exec(
+		`UPDATE agents SET name = ?, url = ?, api_key = ?, ca_file = ?, cert_file = ?, key_file = ?,
+			hostname = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		a.Name, a.URL, a.APIKey, a.CAFile, a.CertFile, a.KeyFile, a.Hostname, a.ID,
+	)
+	if err != nil {
+		return nil, ErrAgentAlreadyExists
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, ErrAgentNotFound
+	}
+
+	return s.GetByID(a.ID)
+}
+
+// Delete removes an agent
+func (s *AgentStore) Delete(id int) error {
+	result, err := s.db.Exec("DELETE FROM agents WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrAgentNotFound
+	}
+	return nil
+}
+
+// EnsureDefaultAgent stores the agent from the config file if no agents exist
+func (s *AgentStore) EnsureDefaultAgent(a *Agent) error {
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM agents").Scan(&count); err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	_, err := s.Create(a)
+	return err
+}
+
+// Close closes the database connection
+func (s *AgentStore) Close() error {
+	return s.db.Close()
+}
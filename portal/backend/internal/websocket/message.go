@@ -4,11 +4,15 @@ package websocket
 type Message struct {
 	Type string      `json:"type"`
 	Data interface{} `json:"data"`
+
+	// AgentID tells which agent an agent_status message is about
+	AgentID int `json:"agent_id,omitempty"`
 }
 
 // Message types
 const (
 	MessageTypeAgentStatus = "agent_status"
+	MessageTypeAgentReport = "agent_report"
 	MessageTypeOBSEvent    = "obs_event"
 	MessageTypeTwitchChat  = "twitch_chat"
 	MessageTypeTwitchEvent = "twitch_event"
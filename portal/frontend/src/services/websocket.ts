@@ -11,6 +11,7 @@ import { useYouTubeStore } from '@/store/youtubeStore'
 export interface WebSocketMessage {
   type: string
   data: unknown
+  agent_id?: number
 }
 
 class WebSocketService {
@@ -68,7 +69,7 @@ class WebSocketService {
   private handleMessage(message: WebSocketMessage) {
     switch (message.type) {
       case 'agent_status':
-        useAgentStore.getState().setStatus(message.data as AgentStatus)
+        useAgentStore.getState().setStatus(message.data as AgentStatus, message.agent_id)
         break
       case 'obs_event':
         this.handleOBSEvent(message.data as OBSEvent)
@@ -2,15 +2,40 @@ import { create } from 'zustand'
 import type { AgentStatus } from '@/types/agent'
 
 interface AgentStore {
+  // Status of the selected agent
   status: AgentStatus | null
+  // Latest status of every agent, keyed by agent ID
+  statuses: Record<number, AgentStatus>
+  selectedAgentId: number | null
   connected: boolean
-  setStatus: (status: AgentStatus) => void
+  setStatus: (status: AgentStatus, agentId?: number) => void
+  selectAgent: (agentId: number) => void
   setConnected: (connected: boolean) => void
 }
 
 export const useAgentStore = create<AgentStore>((set) => ({
   status: null,
+  statuses: {},
+  selectedAgentId: null,
   connected: false,
-  setStatus: (status) => set({ status, connected: true }),
+  setStatus: (status, agentId) =>
+    set((state) => {
+      if (agentId === undefined) {
+        return { status, connected: true }
+      }
+      // Show the first agent that reports until one is selected
+      const selectedAgentId = state.selectedAgentId ?? agentId
+      return {
+        statuses: { ...state.statuses, [agentId]: status },
+        selectedAgentId,
+        status: agentId === selectedAgentId ? status : state.status,
+        connected: true,
+      }
+    }),
+  selectAgent: (agentId) =>
+    set((state) => ({
+      selectedAgentId: agentId,
+      status: state.statuses[agentId] ?? null,
+    })),
   setConnected: (connected) => set({ connected }),
 }))
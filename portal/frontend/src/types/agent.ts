@@ -6,11 +6,36 @@ export interface AgentStatus {
   audio: AudioStatus
   obs: OBSStatus
   gpu: GPUStatus
+  display: DisplayStatus
+  usb: USBStatus
+  expected_devices: ExpectedDevice[]
 }
 
 export interface VideoStatus {
   device_count: number
   devices: string[]
+  details?: VideoDevice[]
+}
+
+export interface VideoDevice {
+  path: string
+  card?: string
+  identity: DeviceIdentity
+}
+
+export interface DeviceIdentity {
+  id?: string
+  by_id: string[]
+  by_path: string[]
+  serial?: string
+  usb?: string
+}
+
+export interface ExpectedDevice {
+  name: string
+  kind: 'video' | 'audio'
+  present: boolean
+  paths: string[]
 }
 
 export interface AudioStatus {
@@ -28,10 +53,71 @@ export interface GPUStatus {
   render_nodes?: string[]
 }
 
+export interface DisplayStatus {
+  available: boolean
+  sessions: DisplaySession[]
+  monitors: Monitor[]
+}
+
+export interface DisplaySession {
+  id: string
+  user: string
+  type: string
+  state: string
+  active: boolean
+  remote: boolean
+}
+
+export interface Monitor {
+  connector: string
+  enabled: boolean
+  name?: string
+  preferred_mode?: string
+  modes: string[]
+}
+
+export interface USBStatus {
+  devices: USBDevice[]
+}
+
+export interface USBDevice {
+  path: string
+  vendor_id: string
+  product_id: string
+  vendor?: string
+  model?: string
+  manufacturer?: string
+  product?: string
+  serial?: string
+  version: string
+  speed_mbps: number
+  speed: string
+  max_speed_mbps?: number
+  port_speed_mbps?: number
+  degraded: boolean
+  max_power_ma: number
+  classes: string[]
+  drivers: string[]
+}
+
 export interface Capabilities {
   can_video: boolean
   can_audio: boolean
   can_stream: boolean
+  load_ok?: boolean
+  warnings?: string[]
+  details?: Record<string, CapabilityResult>
+}
+
+export interface CapabilityResult {
+  available: boolean
+  unmet?: UnmetCondition[]
+}
+
+export interface UnmetCondition {
+  condition: string
+  reason: string
+  actual?: unknown
 }
 
 export interface AgentInfo {